@@ -0,0 +1,61 @@
+package config
+
+// Bootstrap configures the set of nodes and DHT protocol IDs the crawler
+// starts dialing from, instead of the previously hardcoded mix of
+// Filecoin-mainnet and IPFS bootstrap peers.
+type Bootstrap struct {
+	// Peers is a list of multiaddrs to dial at startup, including
+	// "/dnsaddr/..." entries that get resolved through madns.
+	Peers []string `json:"peers"`
+	// Protocols lists the DHT protocol IDs to speak when querying peers.
+	Protocols []string `json:"protocols"`
+	// Network selects a named preset ("filecoin", "ipfs", "custom"). When
+	// set to anything other than "custom", Peers/Protocols default to the
+	// matching preset unless explicitly overridden.
+	Network string `json:"network"`
+}
+
+// Network presets recognised by Bootstrap.Network.
+const (
+	NetworkFilecoin = "filecoin"
+	NetworkIPFS     = "ipfs"
+	NetworkCustom   = "custom"
+)
+
+// FilecoinBootstrapPreset is the first three of the Filecoin mainnet
+// bootstrap peers the crawler used to hardcode.
+var FilecoinBootstrapPreset = []string{
+	"/ip4/3.224.142.21/tcp/1347/p2p/12D3KooWCVe8MmsEMes2FzgTpt9fXtmCY7wrq91GRiaC8PHSCCBj",
+	"/ip4/107.23.112.60/tcp/1347/p2p/12D3KooWCwevHg1yLCvktf2nvLu7L9894mcrJR4MsBCcm4syShVc",
+	"/ip4/100.25.69.197/tcp/1347/p2p/12D3KooWEWVwHGn2yR36gKLozmb4YjDJGerotAPGxmdWZx2nxMC4",
+}
+
+// IPFSBootstrapPreset holds one "/dnsaddr/bootstrap.libp2p.io" entry,
+// resolved at startup, plus one pinned multiaddr taken from the public
+// IPFS DHT bootstrap peers the crawler used to hardcode.
+var IPFSBootstrapPreset = []string{
+	"/dnsaddr/bootstrap.libp2p.io/p2p/QmNnooDu7bfjPFoTZYxMNLWUQJyrVwtbZg5gBMjTezGAJN",
+	"/ip4/104.131.131.82/tcp/4001/ipfs/QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ",
+}
+
+// Resolve returns the Peers/Protocols this Bootstrap should use, applying
+// the named preset when Network != "custom" and no explicit override was
+// given.
+func (b Bootstrap) Resolve() (peers []string, protocols []string) {
+	peers, protocols = b.Peers, b.Protocols
+	if b.Network == NetworkCustom {
+		return peers, protocols
+	}
+	if len(peers) == 0 {
+		switch b.Network {
+		case NetworkIPFS:
+			peers = IPFSBootstrapPreset
+		default:
+			peers = FilecoinBootstrapPreset
+		}
+	}
+	if len(protocols) == 0 {
+		protocols = []string{"/ipfs/kad/1.0.0", "/ipfs/kad/2.0.0"}
+	}
+	return peers, protocols
+}
@@ -0,0 +1,29 @@
+package config
+
+// Transports selects which libp2p transports the crawler host dials and
+// listens on, plus an optional set of circuit-relay v2 peers to reserve a
+// slot on so NAT'd peers advertising only a "/p2p-circuit" address stay
+// reachable. TCP is always enabled; the others default off since they
+// pull in extra transport dependencies the minimal crawl doesn't always
+// need.
+type Transports struct {
+	// QUIC enables "github.com/libp2p/go-libp2p-quic-transport", letting
+	// the host dial and accept "/udp/.../quic" addresses.
+	QUIC bool `json:"quic"`
+	// WebSocket enables the "/tcp/.../ws" and "/tcp/.../wss" transport.
+	WebSocket bool `json:"websocket"`
+	// WebTransport enables the "/udp/.../quic-v1/webtransport" transport.
+	WebTransport bool `json:"webtransport"`
+
+	// RelayPeers are multiaddrs of circuit-relay v2 relays the host
+	// reserves a slot on at startup, so it can still be dialed through
+	// "/p2p/<relay>/p2p-circuit/p2p/<host>" if all of its direct
+	// addresses are NAT'd.
+	RelayPeers []string `json:"relay_peers"`
+}
+
+// DefaultTransports is plain TCP only, matching the crawler's historical
+// behaviour before QUIC/WebSocket/relay support was added.
+func DefaultTransports() Transports {
+	return Transports{}
+}
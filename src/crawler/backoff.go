@@ -0,0 +1,133 @@
+package crawler
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// Backoff defaults, modeled on go-libp2p-discovery's BackoffConnector used
+// by pubsub discovery: full-jitter exponential backoff between dial
+// attempts to the same peer.
+const (
+	backoffMin           = 10 * time.Second
+	backoffMax           = 1 * time.Hour
+	backoffFactor        = 5.0
+	backoffMaxFailures   = 10
+	recentlyDialedLRUCap = 1024
+)
+
+// backoffState is the per-peer bookkeeping kept by BackoffConnector: how
+// many consecutive failures it has accrued and when it's next eligible
+// for a dial attempt.
+type backoffState struct {
+	failures    int
+	nextAttempt time.Time
+	dropped     bool
+}
+
+// BackoffConnector replaces the old permanent-blacklist approach with a
+// per-peer schedule: a peer that fails to dial gets an exponentially
+// growing, jittered cooldown instead of being dropped outright, and is
+// only permanently dropped after maxFailures consecutive failures.
+type BackoffConnector struct {
+	mu    sync.Mutex
+	state map[string]*backoffState
+
+	// recent is an LRU of peer IDs another worker dialed very recently,
+	// so we don't pile multiple workers onto the same peer at once.
+	recent *lru.Cache
+
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	factor      float64
+	maxFailures int
+}
+
+// NewBackoffConnector builds a BackoffConnector with the standard
+// min=10s/max=1h/factor=5.0 schedule and a 1024-entry recently-dialed LRU.
+func NewBackoffConnector() (*BackoffConnector, error) {
+	cache, err := lru.New(recentlyDialedLRUCap)
+	if err != nil {
+		return nil, err
+	}
+	return &BackoffConnector{
+		state:       make(map[string]*backoffState),
+		recent:      cache,
+		minBackoff:  backoffMin,
+		maxBackoff:  backoffMax,
+		factor:      backoffFactor,
+		maxFailures: backoffMaxFailures,
+	}, nil
+}
+
+// Ready reports whether peerID may be dialed right now: it has not been
+// permanently dropped, its next-attempt time has passed, and no other
+// worker dialed it in the last few moments.
+func (b *BackoffConnector) Ready(peerID string) bool {
+	if _, recentlyTried := b.recent.Get(peerID); recentlyTried {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	st, ok := b.state[peerID]
+	if !ok {
+		return true
+	}
+	return !st.dropped && !time.Now().Before(st.nextAttempt)
+}
+
+// MarkAttempted records that a worker is about to dial peerID, so other
+// workers skip it for the duration of the recently-dialed LRU.
+func (b *BackoffConnector) MarkAttempted(peerID string) {
+	b.recent.Add(peerID, struct{}{})
+}
+
+// RecordFailure registers a dial failure, scheduling the next eligible
+// attempt with full-jitter exponential backoff. It reports whether the
+// peer has now exceeded maxFailures and was permanently dropped.
+func (b *BackoffConnector) RecordFailure(peerID string) (dropped bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	st, ok := b.state[peerID]
+	if !ok {
+		st = &backoffState{}
+		b.state[peerID] = st
+	}
+	st.failures++
+	if st.failures >= b.maxFailures {
+		st.dropped = true
+		return true
+	}
+
+	delay := b.delayFor(st.failures)
+	st.nextAttempt = time.Now().Add(delay)
+	return false
+}
+
+// RecordSuccess resets peerID's failure counter after a successful dial.
+func (b *BackoffConnector) RecordSuccess(peerID string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.state, peerID)
+}
+
+// delayFor computes min(maxBackoff, minBackoff * factor^attempts) with
+// full jitter applied (delay = rand.Int63n(computed)).
+func (b *BackoffConnector) delayFor(attempts int) time.Duration {
+	backoff := float64(b.minBackoff)
+	for i := 0; i < attempts; i++ {
+		backoff *= b.factor
+		if backoff >= float64(b.maxBackoff) {
+			backoff = float64(b.maxBackoff)
+			break
+		}
+	}
+	if backoff <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(backoff)))
+}
@@ -0,0 +1,70 @@
+package crawler
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	madns "github.com/multiformats/go-multiaddr-dns"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// dnsaddrRefreshPeriod controls how often resolveBootstrapAddrs is
+// re-run in the background to pick up changes behind a "/dnsaddr/..."
+// entry (e.g. bootstrap.libp2p.io rotating its peer set).
+const dnsaddrRefreshPeriod = 1 * time.Hour
+
+// resolveBootstrapAddrs turns a list of multiaddrs, some of which may be
+// "/dnsaddr/..." entries, into concrete peer.AddrInfo values by resolving
+// the dnsaddr ones through libp2p's madns resolver. Entries that are
+// already concrete multiaddrs are parsed as-is.
+func resolveBootstrapAddrs(ctx context.Context, addrs []string) ([]peer.AddrInfo, error) {
+	resolver, err := madns.NewResolver()
+	if err != nil {
+		return nil, err
+	}
+
+	var resolved []ma.Multiaddr
+	for _, addrStr := range addrs {
+		maddr, err := ma.NewMultiaddr(addrStr)
+		if err != nil {
+			log.Errorf("invalid bootstrap multiaddr %q: %s", addrStr, err.Error())
+			continue
+		}
+		if !strings.Contains(addrStr, "/dnsaddr/") {
+			resolved = append(resolved, maddr)
+			continue
+		}
+		dnsResolved, err := resolver.Resolve(ctx, maddr)
+		if err != nil {
+			log.Errorf("could not resolve dnsaddr %q: %s", addrStr, err.Error())
+			continue
+		}
+		resolved = append(resolved, dnsResolved...)
+	}
+
+	return peer.AddrInfosFromP2pAddrs(resolved...)
+}
+
+// refreshBootstrapPeers re-resolves addrs every dnsaddrRefreshPeriod and
+// feeds the result into onRefresh, until ctx is done. The crawler's
+// onRefresh stores each peer into the DB; the refreshed peers are not
+// added to the DHT routing table.
+func refreshBootstrapPeers(ctx context.Context, addrs []string, onRefresh func([]peer.AddrInfo)) {
+	ticker := time.NewTicker(dnsaddrRefreshPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			peers, err := resolveBootstrapAddrs(ctx, addrs)
+			if err != nil {
+				log.Errorf("could not refresh bootstrap peers: %s", err.Error())
+				continue
+			}
+			onRefresh(peers)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
@@ -1,5 +1,5 @@
 /*
-	Copyright © 2021 Miga Labs
+Copyright © 2021 Miga Labs
 */
 package crawler
 
@@ -7,7 +7,6 @@ import (
 	"context"
 	"os"
 	"os/signal"
-	"sync"
 	"time"
 
 	"github.com/migalabs/armiarma/src/config"
@@ -15,7 +14,6 @@ import (
 	"github.com/migalabs/armiarma/src/exporters"
 	"github.com/migalabs/armiarma/src/hosts"
 	"github.com/migalabs/armiarma/src/info"
-	"github.com/migalabs/armiarma/src/utils"
 
 	"github.com/migalabs/armiarma/src/db/postgresql"
 	"github.com/migalabs/armiarma/src/utils/apis"
@@ -30,39 +28,8 @@ import (
 
 // TEMPORARY data for the running the filecoin demo
 var (
-	workers        = 100
-	minWaitTime    = 5 * time.Second
-	bootstrapNodes = []string{
-		"/ip4/3.224.142.21/tcp/1347/p2p/12D3KooWCVe8MmsEMes2FzgTpt9fXtmCY7wrq91GRiaC8PHSCCBj",
-		"/ip4/107.23.112.60/tcp/1347/p2p/12D3KooWCwevHg1yLCvktf2nvLu7L9894mcrJR4MsBCcm4syShVc",
-		"/ip4/100.25.69.197/tcp/1347/p2p/12D3KooWEWVwHGn2yR36gKLozmb4YjDJGerotAPGxmdWZx2nxMC4",
-		"/ip4/3.123.163.135/tcp/1347/p2p/12D3KooWKhgq8c7NQ9iGjbyK7v7phXvG6492HQfiDaGHLHLQjk7R",
-		"/ip4/18.198.196.213/tcp/1347/p2p/12D3KooWL6PsFNPhYftrJzGgF5U18hFoaVhfGk7xwzD8yVrHJ3Uc",
-		"/ip4/18.195.111.146/tcp/1347/p2p/12D3KooWLFynvDQiUpXoHroV1YxKHhPJgysQGH2k3ZGwtWzR4dFH",
-		"/ip4/52.77.116.139/tcp/1347/p2p/12D3KooWP5MwCiqdMETF9ub1P3MbCvQCcfconnYHbWg6sUJcDRQQ",
-		"/ip4/18.136.2.101/tcp/1347/p2p/12D3KooWRs3aY1p3juFjPy8gPN95PEQChm2QKGUCAdcDCC4EBMKf",
-		"/ip4/13.250.155.222/tcp/1347/p2p/12D3KooWScFR7385LTyR4zU1bYdzSiiAb5rnNABfVahPvVSzyTkR",
-		"/ip4/47.115.22.33/tcp/41778/p2p/12D3KooWDqaZkm3oSczUm3dvAJ5aL2rdSeQ5VQbnHRTQNEFShhmc",
-		"/ip4/61.147.123.111/tcp/12757/p2p/12D3KooWGhufNmZHF3sv48aQeS13ng5XVJZ9E6qy2Ms4VzqeUsHk",
-		"/ip4/61.147.123.121/tcp/12757/p2p/12D3KooWDgQrcyZpcMAkbEFSJJYV2qXEMwXX67WTbqpNdbifHaEq",
-		"/ip4/3.129.112.217/tcp/1235/p2p/12D3KooWBF8cpp65hp2u9LK5mh19x67ftAam84z9LsfaquTDSBpt",
-		"/ip4/36.103.232.198/tcp/34721/p2p/12D3KooWQnwEGNqcM2nAcPtRR9rAX8Hrg4k9kJLCHoTR5chJfz6d",
-		"/ip4/36.103.232.198/tcp/34723/p2p/12D3KooWMKxMkD5DMpSWsW7dBddKxKT7L2GgbNuckz9otxvkvByP",
-		"/ip4/104.131.131.82/tcp/4001/ipfs/QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ",
-		"/ip4/104.236.151.122/tcp/4001/ipfs/QmSoLju6m7xTh3DuokvT3886QRYqxAzb1kShaanJgW36yx",
-		"/ip4/104.236.176.52/tcp/4001/ipfs/QmSoLnSGccFuZQJzRadHn95W2CrSFmZuTdDWP8HXaHca9z",
-		"/ip4/104.236.179.241/tcp/4001/ipfs/QmSoLpPVmHKQ4XTPdz8tjDFgdeRFkpV8JgYq8JVJ69RrZm",
-		"/ip4/104.236.76.40/tcp/4001/ipfs/QmSoLV4Bbm51jM9C4gDYZQ9Cy3U6aXMJDAbzgu2fzaDs64",
-		"/ip4/128.199.219.111/tcp/4001/ipfs/QmSoLSafTMBsPKadTEgaXctDQVcqN88CNLHXMkTNwMKPnu",
-		"/ip4/162.243.248.213/tcp/4001/ipfs/QmSoLueR4xBeUbY9WZ9xGUUxunbKWcrNFTDAadQJmocnWm",
-		"/ip4/178.62.158.247/tcp/4001/ipfs/QmSoLer265NRgSp2LA3dPaeykiS1J6DifTC88f5uVQKNAd",
-		"/ip4/178.62.61.185/tcp/4001/ipfs/QmSoLMeWqB7YGVLJN3pNLQpmmEk35v6wYtsMGLzSr5QBU3",
-	}
-	protocols = []string{
-		"/ipfs/kad/1.0.0",
-		"/ipfs/kad/2.0.0",
-		"/dnsaddr/bootstrap.libp2p.io",
-	}
+	workers     = 100
+	minWaitTime = 5 * time.Second
 )
 
 // crawler status containing the main basemodule and info that the app will ConnectedF
@@ -76,6 +43,27 @@ type FilecoinCrawler struct {
 	Info            *info.InfoData
 	IpLocalizer     apis.PeerLocalizer
 	ExporterService *exporters.ExporterService
+
+	Bootstrap      config.Bootstrap
+	bootstrapPeers []string
+
+	// GossipsubTopics lists the topics GossipsubDiscovery joins to harvest
+	// peers via gossipsub peer-exchange (e.g. "/fil/blocks/<network>").
+	GossipsubTopics []string
+
+	persistent *persistentPeerManager
+}
+
+// MarkPersistent pins peerID so the persistent-peer manager keeps it
+// continuously connected, reconnecting on disconnect with the same
+// backoff schedule used for regular dials.
+func (c *FilecoinCrawler) MarkPersistent(peerID peer.ID) {
+	c.persistent.MarkPersistent(peerID)
+}
+
+// UnmarkPersistent stops keeping peerID continuously connected.
+func (c *FilecoinCrawler) UnmarkPersistent(peerID peer.ID) {
+	c.persistent.UnmarkPersistent(peerID)
 }
 
 func NewFilecoinCrawler(ctx context.Context, config config.ConfigData) (*FilecoinCrawler, error) {
@@ -88,7 +76,7 @@ func NewFilecoinCrawler(ctx context.Context, config config.ConfigData) (*Filecoi
 	// Neccessary secuence for setting up the network crawler
 	// 1. Create Host
 	log.Info("creating host")
-	host, err := hosts.NewBasicLibp2pFilecoin2Host(mainCtx, *infoObj, &ipLocalizer, &db)
+	host, err := hosts.NewBasicLibp2pFilecoin2Host(mainCtx, *infoObj, &ipLocalizer, &db, config.Transports)
 	if err != nil {
 		return nil, err
 	}
@@ -103,6 +91,10 @@ func NewFilecoinCrawler(ctx context.Context, config config.ConfigData) (*Filecoi
 	// 3. Create the Exporting Service
 	// exporterService := exporters.NewExporterService(mainCtx)
 
+	// Resolve the configured bootstrap peers/protocols, falling back to
+	// the network preset (filecoin/ipfs) when none were given explicitly.
+	bootstrapPeers, protocols := config.Bootstrap.Resolve()
+
 	// Generate necessary messenger for requesting near peers
 	ms := &msgSender{
 		h:         h,
@@ -125,6 +117,9 @@ func NewFilecoinCrawler(ctx context.Context, config config.ConfigData) (*Filecoi
 		DB:              psql,
 		IpLocalizer:     ipLocalizer,
 		ExporterService: exporterService,
+		Bootstrap:       config.Bootstrap,
+		bootstrapPeers:  bootstrapPeers,
+		GossipsubTopics: config.GossipsubTopics,
 	}
 	return crawler, nil
 }
@@ -170,13 +165,44 @@ func (c *FilecoinCrawler) crawlNetwork() {
 	connectablePeers := NewDiscoveryPeers(c.ctx, c.DB)
 	npeer := connectablePeers.Run()
 
-	// Fill with bootstrap nodes
+	// Gossipsub-based peer exchange, running alongside the DHT crawl and
+	// feeding the same worker pool through connectablePeers.Feed. Each PX
+	// peer is stored with whatever addresses it came with first, same as
+	// refreshBootstrapPeers below, since the worker pool can only dial
+	// peers that already have a row with addresses in the DB.
+	if len(c.GossipsubTopics) > 0 {
+		gsDiscovery, err := NewGossipsubDiscovery(c.ctx, c.Host, c.DB, c.GossipsubTopics)
+		if err != nil {
+			log.Errorf("could not start gossipsub discovery: %s", err.Error())
+		} else {
+			gsDiscovery.Run(func(ai peer.AddrInfo) {
+				c.DB.StoreFilecoinPeer(ai.ID.String(), c.ExtractHostInfo(ai))
+				connectablePeers.Feed(ai.ID.String())
+			})
+		}
+	}
+
+	// Fill with bootstrap nodes, resolving any "/dnsaddr/..." entries
+	// through madns so calibnet/ipfs/custom presets all work unmodified.
 	log.Info("connecting to the bootstrap nodes")
-	for _, peerAddr := range bootstrapNodes {
-		maddr, _ := utils.UnmarshalMaddr(peerAddr)
-		peerInfo, _ := peer.AddrInfoFromP2pAddr(maddr)
+	resolvedBootstrap, err := resolveBootstrapAddrs(c.ctx, c.bootstrapPeers)
+	if err != nil {
+		log.Error(err)
+	}
+	go refreshBootstrapPeers(c.ctx, c.bootstrapPeers, func(peers []peer.AddrInfo) {
+		for _, peerInfo := range peers {
+			c.DB.StoreFilecoinPeer(peerInfo.ID.String(), c.ExtractHostInfo(peerInfo))
+		}
+	})
+	persistent, err := newPersistentPeerManager(c.ctx, h, c.DB, resolvedBootstrap)
+	if err != nil {
+		log.Error(err)
+	}
+	c.persistent = persistent
+
+	for _, peerInfo := range resolvedBootstrap {
 		// Load it to the sync map
-		p := c.ExtractHostInfo(*peerInfo)
+		p := c.ExtractHostInfo(peerInfo)
 		c.DB.StoreFilecoinPeer(peerInfo.ID.String(), p)
 	}
 
@@ -214,15 +240,39 @@ func (c *FilecoinCrawler) crawlNetwork() {
 						continue
 					}
 					log.Debugf(" connecting", pid)
+					connectablePeers.backoff.MarkAttempted(pid)
 					if err := h.Connect(c.ctx, maddr); err != nil {
 						log.Error(err.Error())
-						// remove unreacheable node from the list
-						connectablePeers.Blacklist(pid)
+						// schedule a jittered backoff instead of dropping
+						// the peer outright; only permanently dropped
+						// after backoffMaxFailures consecutive failures
+						if dropped := connectablePeers.backoff.RecordFailure(pid); dropped {
+							log.Debugf("peer %s exceeded max dial failures, dropping", pid)
+						}
 
 					} else {
+						connectablePeers.backoff.RecordSuccess(pid)
 						log.Debug("Connection established with bootstrap node:" + pid)
 						// If peer was connectable, req all the possible info from the peer and save it in the PSQL
 						fpeer := c.ExtractHostInfo(maddr)
+						// Record which transport the successful dial used
+						// (TCP/QUIC/WebSocket/circuit-relay) so the dataset
+						// can quantify transport adoption across the network.
+						transport := hosts.TransportForMaddr(maddr.Addrs[0])
+						if conns := h.Network().ConnsToPeer(maddr.ID); len(conns) > 0 {
+							transport = hosts.TransportForMaddr(conns[0].RemoteMultiaddr())
+						}
+						c.DB.StorePeerTransport(fpeer.PeerId, transport)
+						// identify has had a chance to exchange envelopes by
+						// now; pull and verify the peer's signed routing
+						// record so downstream analysis can tell
+						// authenticated addresses from DHT hearsay.
+						spr, err := extractSignedPeerRecord(h, maddr.ID)
+						if err != nil {
+							log.Debugf("could not extract signed peer record for %s: %s", pid, err.Error())
+						} else if spr.Envelope != nil {
+							c.DB.StoreSignedPeerRecord(fpeer.PeerId, spr.Envelope, spr.Seq, spr.Verified)
+						}
 						c.DB.StoreFilecoinPeer(fpeer.PeerId, fpeer)
 						// try to request neighbors to connected peer
 						neighborsRt, err := c.fetchNeighbors(c.ctx, maddr)
@@ -254,14 +304,8 @@ func (c *FilecoinCrawler) crawlNetwork() {
 		for {
 			select {
 			case <-ticker.C:
-				// count blacklisted peers
-				blacklisted := 0
-				connectablePeers.blacklist.Range(func(key, value interface{}) bool {
-					blacklisted++
-					return true
-				})
 				connpeers := c.DB.GetFilecoinPeers()
-				log.Infof("SUMMARY: %d discovered peers, %d blacklisted", len(connpeers), blacklisted)
+				log.Infof("SUMMARY: %d discovered peers, persistent peers uptime: %s", len(connpeers), c.persistent.Uptime())
 			case <-c.ctx.Done():
 				log.Info("closing routing")
 				return
@@ -292,7 +336,7 @@ func (c *FilecoinCrawler) crawlNetwork() {
 type discoveredPeers struct {
 	ctx       context.Context
 	pArray    []string
-	blacklist sync.Map
+	backoff   *BackoffConnector
 	nPeerChan chan string
 	nPeerReq  chan struct{}
 
@@ -300,9 +344,14 @@ type discoveredPeers struct {
 }
 
 func NewDiscoveryPeers(ctx context.Context, db *postgresql.PostgresDBService) discoveredPeers {
+	backoff, err := NewBackoffConnector()
+	if err != nil {
+		log.Panic(err)
+	}
 	dp := discoveredPeers{
 		ctx:       ctx,
 		pArray:    make([]string, 0),
+		backoff:   backoff,
 		nPeerChan: make(chan string, workers),
 		nPeerReq:  make(chan struct{}, workers),
 		db:        db,
@@ -311,9 +360,15 @@ func NewDiscoveryPeers(ctx context.Context, db *postgresql.PostgresDBService) di
 	return dp
 }
 
+// refreshPeerList repopulates the in-memory peer list from the DB. Peers
+// with a verified signed_peer_record are appended ahead of ones only
+// ever seen through unauthenticated DHT FindNode responses, so workers
+// dial authenticated addresses first; see
+// postgresql.PostgresDBService.StoreSignedPeerRecord/HasVerifiedSignedPeerRecord.
 func (d *discoveredPeers) refreshPeerList() {
 	log.Debug("refreshing peer list")
 	cnt := 0
+	var verified, rest []string
 	// poblate the dp with peers in the DB
 	peers := d.db.GetFilecoinPeers()
 	for _, pID := range peers {
@@ -321,11 +376,16 @@ func (d *discoveredPeers) refreshPeerList() {
 		if !ok {
 			continue
 		}
-		// add the
-		d.pArray = append(d.pArray, pID.String())
+		if d.db.HasVerifiedSignedPeerRecord(pID.String()) {
+			verified = append(verified, pID.String())
+		} else {
+			rest = append(rest, pID.String())
+		}
 		cnt++
 	}
-	log.Debugf("refreshed peerstore with %d peers", cnt)
+	d.pArray = append(d.pArray, verified...)
+	d.pArray = append(d.pArray, rest...)
+	log.Debugf("refreshed peerstore with %d peers (%d verified SPR)", cnt, len(verified))
 }
 
 func (d *discoveredPeers) Run() chan string {
@@ -341,7 +401,9 @@ func (d *discoveredPeers) Run() chan string {
 				if len(d.pArray) != 0 {
 					pid := d.pArray[pointer]
 					pointer++
-					if d.isBlacklisted(pid) {
+					// skip peers whose next-attempt time is still in the
+					// future, rather than removing them from the list
+					if !d.backoff.Ready(pid) {
 						d.nPeerReq <- struct{}{}
 						continue
 					}
@@ -373,12 +435,11 @@ func (d *discoveredPeers) ReqNextPeer() {
 	d.nPeerReq <- struct{}{}
 }
 
-func (d *discoveredPeers) Blacklist(peerID string) {
-	d.blacklist.Store(peerID, struct{}{})
-}
-
-func (d *discoveredPeers) isBlacklisted(peerID string) bool {
-	// get pointer of the peerID
-	_, ok := d.blacklist.Load(peerID)
-	return ok
+// Feed injects a peer ID discovered outside the DHT crawl (e.g. by
+// GossipsubDiscovery) straight into the worker pool's peer channel.
+func (d *discoveredPeers) Feed(peerID string) {
+	select {
+	case d.nPeerChan <- peerID:
+	case <-d.ctx.Done():
+	}
 }
@@ -0,0 +1,120 @@
+package crawler
+
+import (
+	"context"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	"github.com/migalabs/armiarma/src/db/postgresql"
+	"github.com/migalabs/armiarma/src/hosts"
+)
+
+// pxQueueSize bounds how many PX peers can be waiting for processPXPeers
+// to pick them up; RecvRPC drops peers past this rather than block.
+const pxQueueSize = workers
+
+// GossipsubDiscovery is a second peer-discovery source alongside the
+// Kademlia DHT crawl: it joins one or more gossipsub topics with peer
+// exchange enabled and, through pxTracer, harvests the peers actually
+// handed out via PX on PRUNE — not the peers it merely sees relaying
+// messages on the topic, which peer exchange never touches.
+type GossipsubDiscovery struct {
+	ctx    context.Context
+	host   *hosts.BasicLibp2pHost
+	topics []string
+	ps     *pubsub.PubSub
+	db     *postgresql.PostgresDBService
+
+	onPeer func(peer.AddrInfo)
+	pxChan chan pxPeer
+}
+
+// NewGossipsubDiscovery builds a GossipsubDiscovery over the given
+// topics, with gossipsub peer exchange enabled so meshed peers hand out
+// replacement peers on PRUNE, and a pxTracer attached to harvest them.
+func NewGossipsubDiscovery(ctx context.Context, h *hosts.BasicLibp2pHost, db *postgresql.PostgresDBService, topics []string) (*GossipsubDiscovery, error) {
+	gd := &GossipsubDiscovery{
+		ctx:    ctx,
+		host:   h,
+		topics: topics,
+		db:     db,
+		onPeer: func(peer.AddrInfo) {},
+		pxChan: make(chan pxPeer, pxQueueSize),
+	}
+	// enqueuePXPeer only ever does a non-blocking channel send: RecvRPC
+	// runs on pubsub's own event-processing goroutine, so the actual DB
+	// write and onPeer forwarding (both of which can block) happen on
+	// processPXPeers instead, off that critical path.
+	tracer := newPXTracer(gd.enqueuePXPeer)
+	ps, err := pubsub.NewGossipSub(ctx, h.Host(), pubsub.WithPeerExchange(true), pubsub.WithRawTracer(tracer))
+	if err != nil {
+		return nil, err
+	}
+	gd.ps = ps
+	return gd, nil
+}
+
+// Run joins every configured topic, so this host is meshed and on the
+// receiving end of PX-bearing PRUNE traffic for it, and forwards every
+// peer pxTracer harvests to onPeer. The crawler's onPeer stores the peer
+// with whatever addresses its signed peer record carried and then hands
+// it to discoveredPeers' Feed, so the DHT worker pool can actually dial it.
+func (gd *GossipsubDiscovery) Run(onPeer func(peer.AddrInfo)) {
+	gd.onPeer = onPeer
+	go gd.processPXPeers()
+	for _, topicName := range gd.topics {
+		topic, err := gd.ps.Join(topicName)
+		if err != nil {
+			log.Errorf("gossipsub discovery: could not join topic %s: %s", topicName, err.Error())
+			continue
+		}
+		sub, err := topic.Subscribe()
+		if err != nil {
+			log.Errorf("gossipsub discovery: could not subscribe to topic %s: %s", topicName, err.Error())
+			continue
+		}
+		// drain() keeps the subscription's message queue from filling up;
+		// pxTracer, not the message stream, is what actually discovers
+		// peers here.
+		go gd.drain(topicName, sub)
+	}
+}
+
+func (gd *GossipsubDiscovery) drain(topicName string, sub *pubsub.Subscription) {
+	for {
+		if _, err := sub.Next(gd.ctx); err != nil {
+			log.Debugf("gossipsub discovery: subscription to %s closed: %s", topicName, err.Error())
+			return
+		}
+	}
+}
+
+// enqueuePXPeer is pxTracer's onPeer callback. It never blocks: a full
+// pxChan means processPXPeers is falling behind, in which case dropping
+// this PX peer is preferable to stalling pubsub's event loop.
+func (gd *GossipsubDiscovery) enqueuePXPeer(px pxPeer) {
+	select {
+	case gd.pxChan <- px:
+	default:
+		log.Debugf("gossipsub discovery: PX queue full, dropping peer %s", px.AddrInfo.ID)
+	}
+}
+
+// processPXPeers drains pxChan, recording the topic each PX-harvested peer
+// was handed out on (StoreGossipsubPeerTopic) and forwarding it to onPeer,
+// which stores its addresses. Both can block (SQL writes,
+// connectablePeers.Feed), so
+// this runs off pubsub's own RecvRPC goroutine entirely.
+func (gd *GossipsubDiscovery) processPXPeers() {
+	for {
+		select {
+		case <-gd.ctx.Done():
+			return
+		case px := <-gd.pxChan:
+			if err := gd.db.StoreGossipsubPeerTopic(px.AddrInfo.ID.String(), px.Topic); err != nil {
+				log.Debugf("gossipsub discovery: could not store PX peer %s: %s", px.AddrInfo.ID, err.Error())
+			}
+			gd.onPeer(px.AddrInfo)
+		}
+	}
+}
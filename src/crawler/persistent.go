@@ -0,0 +1,170 @@
+package crawler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/migalabs/armiarma/src/db/postgresql"
+	ma "github.com/multiformats/go-multiaddr"
+)
+
+// Connection lifecycle event kinds, written to the peer_connection_events
+// table so long-term uptime of pinned infrastructure peers can be
+// reconstructed later.
+const (
+	ConnEventConnected    = "connected"
+	ConnEventDisconnected = "disconnected"
+)
+
+// persistentPeerManager keeps a set of peers (the seed peers it is built
+// with plus any added through MarkPersistent) continuously connected,
+// borrowing the persistent-peers idea from Tendermint: on disconnect, a
+// reconnect is scheduled subject to the same exponential-backoff used for
+// regular dials. The persistent_peers table is not read at startup, so
+// peers pinned in an earlier run are not part of the set.
+type persistentPeerManager struct {
+	ctx  context.Context
+	h    host.Host
+	db   *postgresql.PostgresDBService
+	conn *BackoffConnector
+
+	mu        sync.Mutex
+	peers     map[peer.ID]struct{}
+	connSince map[peer.ID]time.Time
+}
+
+// newPersistentPeerManager builds a persistentPeerManager seeded with the
+// given peers, registering a network.Notifiee so Disconnected events on
+// any of them trigger a scheduled reconnect.
+func newPersistentPeerManager(ctx context.Context, h host.Host, db *postgresql.PostgresDBService, seed []peer.AddrInfo) (*persistentPeerManager, error) {
+	backoff, err := NewBackoffConnector()
+	if err != nil {
+		return nil, err
+	}
+	pm := &persistentPeerManager{
+		ctx:       ctx,
+		h:         h,
+		db:        db,
+		conn:      backoff,
+		peers:     make(map[peer.ID]struct{}),
+		connSince: make(map[peer.ID]time.Time),
+	}
+	for _, ai := range seed {
+		h.Peerstore().AddAddrs(ai.ID, ai.Addrs, time.Hour)
+		pm.peers[ai.ID] = struct{}{}
+	}
+	h.Network().Notify(pm)
+	return pm, nil
+}
+
+// MarkPersistent pins peerID so it is kept continuously connected.
+func (pm *persistentPeerManager) MarkPersistent(peerID peer.ID) {
+	pm.mu.Lock()
+	pm.peers[peerID] = struct{}{}
+	pm.mu.Unlock()
+	if err := pm.db.MarkPersistent(peerID.String()); err != nil {
+		log.Errorf("could not persist %s as a persistent peer: %s", peerID, err.Error())
+	}
+}
+
+// UnmarkPersistent stops keeping peerID continuously connected.
+func (pm *persistentPeerManager) UnmarkPersistent(peerID peer.ID) {
+	pm.mu.Lock()
+	delete(pm.peers, peerID)
+	pm.mu.Unlock()
+	if err := pm.db.UnmarkPersistent(peerID.String()); err != nil {
+		log.Errorf("could not unmark %s as a persistent peer: %s", peerID, err.Error())
+	}
+}
+
+func (pm *persistentPeerManager) isPersistent(peerID peer.ID) bool {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	_, ok := pm.peers[peerID]
+	return ok
+}
+
+// Connected implements network.Notifiee. Connection events are only
+// persisted for pinned persistent peers: logging every peer the crawl
+// dials would make peer_connection_events grow unbounded with the rest
+// of the crawl.
+func (pm *persistentPeerManager) Connected(_ network.Network, c network.Conn) {
+	p := c.RemotePeer()
+	pm.conn.RecordSuccess(p.String())
+	if !pm.isPersistent(p) {
+		return
+	}
+	pm.mu.Lock()
+	pm.connSince[p] = time.Now()
+	pm.mu.Unlock()
+	if err := pm.db.StorePeerConnectionEvent(p.String(), ConnEventConnected, time.Now()); err != nil {
+		log.Errorf("could not store connection event for persistent peer %s: %s", p, err.Error())
+	}
+}
+
+// Disconnected implements network.Notifiee. For persistent peers, it
+// records the disconnection and schedules a reconnect subject to the
+// BackoffConnector's schedule.
+func (pm *persistentPeerManager) Disconnected(_ network.Network, c network.Conn) {
+	p := c.RemotePeer()
+	if !pm.isPersistent(p) {
+		return
+	}
+	if err := pm.db.StorePeerConnectionEvent(p.String(), ConnEventDisconnected, time.Now()); err != nil {
+		log.Errorf("could not store disconnection event for persistent peer %s: %s", p, err.Error())
+	}
+	pm.mu.Lock()
+	delete(pm.connSince, p)
+	pm.mu.Unlock()
+	go pm.reconnect(p)
+}
+
+// Uptime reports, for the SUMMARY log line, how many of the pinned
+// persistent peers are currently connected and the longest any of them
+// has been connected without interruption.
+func (pm *persistentPeerManager) Uptime() string {
+	if pm == nil {
+		return "persistent peer manager not running"
+	}
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	var longest time.Duration
+	connected := 0
+	for _, since := range pm.connSince {
+		connected++
+		if up := time.Since(since); up > longest {
+			longest = up
+		}
+	}
+	return fmt.Sprintf("%d/%d connected, longest %s", connected, len(pm.peers), longest.Round(time.Second))
+}
+
+func (pm *persistentPeerManager) reconnect(p peer.ID) {
+	for {
+		if !pm.conn.Ready(p.String()) {
+			select {
+			case <-time.After(backoffMin):
+			case <-pm.ctx.Done():
+				return
+			}
+			continue
+		}
+		addrs := pm.h.Peerstore().Addrs(p)
+		if err := pm.h.Connect(pm.ctx, peer.AddrInfo{ID: p, Addrs: addrs}); err != nil {
+			log.Debugf("persistent peer %s reconnect failed: %s", p, err.Error())
+			pm.conn.RecordFailure(p.String())
+			continue
+		}
+		return
+	}
+}
+
+// Listen, ListenClose implement network.Notifiee with no-ops; only
+// connection lifecycle events matter here.
+func (pm *persistentPeerManager) Listen(network.Network, ma.Multiaddr)      {}
+func (pm *persistentPeerManager) ListenClose(network.Network, ma.Multiaddr) {}
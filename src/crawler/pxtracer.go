@@ -0,0 +1,83 @@
+package crawler
+
+import (
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/peerstore"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/libp2p/go-libp2p-core/record"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pb "github.com/libp2p/go-libp2p-pubsub/pb"
+)
+
+// pxPeer is a peer handed out through gossipsub's peer-exchange protocol,
+// carrying whatever addresses its signed peer record envelope verified
+// (if the pruning peer included one) so it can be dialed without a
+// separate DHT lookup.
+type pxPeer struct {
+	Topic    string
+	AddrInfo peer.AddrInfo
+	Verified bool
+}
+
+// pxTracer is a pubsub.RawTracer that only cares about PRUNE messages:
+// peer exchange hands out replacement peers through
+// pb.ControlPrune.Peers, not through the regular message stream, so
+// reading msg.GetFrom() off delivered messages only ever sees whichever
+// peer relayed a message to us and never touches PX at all.
+type pxTracer struct {
+	onPeer func(pxPeer)
+}
+
+// newPXTracer builds a pxTracer that reports every PX peer record it sees
+// pruned off a mesh to onPeer.
+func newPXTracer(onPeer func(pxPeer)) *pxTracer {
+	return &pxTracer{onPeer: onPeer}
+}
+
+func (t *pxTracer) RecvRPC(rpc *pb.RPC) {
+	ctrl := rpc.GetControl()
+	if ctrl == nil {
+		return
+	}
+	for _, prune := range ctrl.GetPrune() {
+		topic := prune.GetTopicID()
+		for _, pi := range prune.GetPeers() {
+			t.handlePeerInfo(topic, pi)
+		}
+	}
+}
+
+func (t *pxTracer) handlePeerInfo(topic string, pi *pb.PeerInfo) {
+	id, err := peer.IDFromBytes(pi.GetPeerID())
+	if err != nil || id == "" {
+		return
+	}
+	px := pxPeer{Topic: topic, AddrInfo: peer.AddrInfo{ID: id}}
+	if envBytes := pi.GetSignedPeerRecord(); len(envBytes) > 0 {
+		envelope, untypedRecord, err := record.ConsumeEnvelope(envBytes, peerstore.PeerRecordEnvelopeDomain)
+		if err == nil {
+			if pr, ok := untypedRecord.(*peerstore.PeerRecord); ok && envelope != nil {
+				px.AddrInfo.Addrs = pr.Addrs
+				px.Verified = true
+			}
+		}
+	}
+	t.onPeer(px)
+}
+
+// The remaining pubsub.RawTracer callbacks carry nothing peer-exchange
+// cares about; pxTracer only acts on RecvRPC.
+func (t *pxTracer) AddPeer(peer.ID, protocol.ID)          {}
+func (t *pxTracer) RemovePeer(peer.ID)                    {}
+func (t *pxTracer) Join(string)                           {}
+func (t *pxTracer) Leave(string)                          {}
+func (t *pxTracer) Graft(peer.ID, string)                 {}
+func (t *pxTracer) Prune(peer.ID, string)                 {}
+func (t *pxTracer) ValidateMessage(*pubsub.Message)       {}
+func (t *pxTracer) DeliverMessage(*pubsub.Message)        {}
+func (t *pxTracer) RejectMessage(*pubsub.Message, string) {}
+func (t *pxTracer) DuplicateMessage(*pubsub.Message)      {}
+func (t *pxTracer) ThrottlePeer(peer.ID)                  {}
+func (t *pxTracer) SendRPC(*pb.RPC, peer.ID)              {}
+func (t *pxTracer) DropRPC(*pb.RPC, peer.ID)              {}
+func (t *pxTracer) UndeliverableMessage(*pubsub.Message)  {}
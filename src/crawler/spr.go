@@ -0,0 +1,60 @@
+package crawler
+
+import (
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/peerstore"
+	"github.com/libp2p/go-libp2p-core/record"
+)
+
+// SignedPeerRecord is the verified (or not) routing record a peer handed
+// us through identify, kept separate from the regular Filecoin peer row
+// so unauthenticated DHT FindNode hearsay can be told apart from
+// cryptographically signed addresses.
+type SignedPeerRecord struct {
+	Envelope []byte
+	Seq      uint64
+	Verified bool
+}
+
+// extractSignedPeerRecord pulls p's signed peer record envelope out of the
+// host's peerstore (populated by identify after a successful connection),
+// verifies the envelope's signature against p's public key, and returns
+// the raw envelope bytes plus its sequence number.
+//
+// Call this right after h.Connect succeeds, once identify has had a
+// chance to exchange envelopes. The crawler stores the result through
+// PostgresDBService.StoreSignedPeerRecord, in its own signed_peer_record
+// table keyed by peer ID rather than on the peer row.
+func extractSignedPeerRecord(h host.Host, p peer.ID) (*SignedPeerRecord, error) {
+	cab, ok := peerstore.GetCertifiedAddrBook(h.Peerstore())
+	if !ok {
+		return &SignedPeerRecord{}, nil
+	}
+	envelope := cab.GetPeerRecord(p)
+	if envelope == nil {
+		return &SignedPeerRecord{}, nil
+	}
+
+	data, err := envelope.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	// Re-consuming the envelope we just got from our own peerstore verifies
+	// its signature against the embedded public key; ConsumeEnvelope
+	// returns a non-nil error on a bad signature.
+	_, _, err = record.ConsumeEnvelope(data, peerstore.PeerRecordEnvelopeDomain)
+
+	rec, castOk := envelope.Record().(*peerstore.PeerRecord)
+	var seq uint64
+	if castOk {
+		seq = rec.Seq
+	}
+
+	return &SignedPeerRecord{
+		Envelope: data,
+		Seq:      seq,
+		Verified: err == nil,
+	}, nil
+}
@@ -0,0 +1,28 @@
+package postgresql
+
+import "context"
+
+func init() {
+	schemaMigrations = append(schemaMigrations, gossipsubPeerTopicSchema)
+}
+
+const gossipsubPeerTopicSchema = `
+CREATE TABLE IF NOT EXISTS gossipsub_peer_topic (
+	peer_id TEXT NOT NULL,
+	topic TEXT NOT NULL,
+	seen_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+	PRIMARY KEY (peer_id, topic)
+);
+`
+
+// StoreGossipsubPeerTopic records that peerId was handed out through
+// gossipsub peer exchange on topic; see crawler.pxTracer, which is the
+// only place this is actually populated from.
+func (p *PostgresDBService) StoreGossipsubPeerTopic(peerId string, topic string) error {
+	_, err := p.db.ExecContext(context.Background(), `
+		INSERT INTO gossipsub_peer_topic (peer_id, topic, seen_at)
+		VALUES ($1, $2, now())
+		ON CONFLICT (peer_id, topic) DO UPDATE SET seen_at = now()
+	`, peerId, topic)
+	return err
+}
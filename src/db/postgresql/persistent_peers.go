@@ -0,0 +1,54 @@
+package postgresql
+
+import (
+	"context"
+	"time"
+)
+
+func init() {
+	schemaMigrations = append(schemaMigrations, peerConnectionEventsSchema, persistentPeersSchema)
+}
+
+const peerConnectionEventsSchema = `
+CREATE TABLE IF NOT EXISTS peer_connection_events (
+	id BIGSERIAL PRIMARY KEY,
+	peer_id TEXT NOT NULL,
+	event TEXT NOT NULL,
+	event_time TIMESTAMPTZ NOT NULL
+);
+`
+
+const persistentPeersSchema = `
+CREATE TABLE IF NOT EXISTS persistent_peers (
+	peer_id TEXT PRIMARY KEY
+);
+`
+
+// StorePeerConnectionEvent records a connected/disconnected lifecycle
+// event for peerId, used to reconstruct persistent-peer uptime; see
+// crawler.persistentPeerManager and ConnEventConnected/ConnEventDisconnected.
+// Only called for the persistent-peer set (the seed bootstrap peers and
+// peers pinned via MarkPersistent), to keep write volume bounded to that
+// (small) set rather than every dial.
+func (p *PostgresDBService) StorePeerConnectionEvent(peerId string, event string, at time.Time) error {
+	_, err := p.db.ExecContext(context.Background(),
+		`INSERT INTO peer_connection_events (peer_id, event, event_time) VALUES ($1, $2, $3)`,
+		peerId, event, at)
+	return err
+}
+
+// MarkPersistent records peerId in the persistent_peers table; see
+// crawler.FilecoinCrawler.MarkPersistent. The crawler does not read this
+// table back at startup, so a pin does not carry over a restart.
+func (p *PostgresDBService) MarkPersistent(peerId string) error {
+	_, err := p.db.ExecContext(context.Background(),
+		`INSERT INTO persistent_peers (peer_id) VALUES ($1) ON CONFLICT DO NOTHING`, peerId)
+	return err
+}
+
+// UnmarkPersistent undoes MarkPersistent.
+func (p *PostgresDBService) UnmarkPersistent(peerId string) error {
+	_, err := p.db.ExecContext(context.Background(),
+		`DELETE FROM persistent_peers WHERE peer_id = $1`, peerId)
+	return err
+}
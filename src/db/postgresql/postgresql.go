@@ -0,0 +1,46 @@
+/*
+	Copyright © 2021 Miga Labs
+*/
+// Package postgresql is the crawler's Postgres-backed peerstore.
+// GetFilecoinPeers, LoadFilecoinPeer and StoreFilecoinPeer (the core
+// peer-row table) live alongside the tables added here; this file owns
+// the connection itself plus the schema-migration plumbing the newer
+// tables hook into through schemaMigrations.
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+
+	_ "github.com/lib/pq"
+)
+
+// PostgresDBService wraps the crawler's connection to the Postgres
+// peerstore database.
+type PostgresDBService struct {
+	ctx context.Context
+	db  *sql.DB
+}
+
+// schemaMigrations lists the "CREATE TABLE IF NOT EXISTS" statements run
+// once at connect time, one entry per table added after the original
+// peer-row schema; each feature file appends its own via init().
+var schemaMigrations []string
+
+// ConnectToDB opens a connection to the Postgres instance at endpoint and
+// runs every registered schema migration.
+func ConnectToDB(ctx context.Context, endpoint string) (*PostgresDBService, error) {
+	sqlDB, err := sql.Open("postgres", endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if err := sqlDB.PingContext(ctx); err != nil {
+		return nil, err
+	}
+	for _, stmt := range schemaMigrations {
+		if _, err := sqlDB.ExecContext(ctx, stmt); err != nil {
+			return nil, err
+		}
+	}
+	return &PostgresDBService{ctx: ctx, db: sqlDB}, nil
+}
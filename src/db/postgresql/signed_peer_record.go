@@ -0,0 +1,49 @@
+package postgresql
+
+import "context"
+
+func init() {
+	schemaMigrations = append(schemaMigrations, signedPeerRecordSchema)
+}
+
+const signedPeerRecordSchema = `
+CREATE TABLE IF NOT EXISTS signed_peer_record (
+	peer_id TEXT PRIMARY KEY,
+	envelope BYTEA NOT NULL,
+	spr_seq BIGINT NOT NULL,
+	spr_verified BOOLEAN NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+`
+
+// StoreSignedPeerRecord upserts peerId's signed routing-record envelope,
+// called once identify has had a chance to exchange envelopes after a
+// successful dial. spr_verified records whether the envelope's signature
+// checked out against the peer's public key; see
+// crawler.extractSignedPeerRecord.
+func (p *PostgresDBService) StoreSignedPeerRecord(peerId string, envelope []byte, seq uint64, verified bool) error {
+	_, err := p.db.ExecContext(context.Background(), `
+		INSERT INTO signed_peer_record (peer_id, envelope, spr_seq, spr_verified, updated_at)
+		VALUES ($1, $2, $3, $4, now())
+		ON CONFLICT (peer_id) DO UPDATE SET
+			envelope = EXCLUDED.envelope,
+			spr_seq = EXCLUDED.spr_seq,
+			spr_verified = EXCLUDED.spr_verified,
+			updated_at = now()
+	`, peerId, envelope, seq, verified)
+	return err
+}
+
+// HasVerifiedSignedPeerRecord reports whether peerId has a signed peer
+// record on file whose envelope signature was verified, so callers can
+// prioritize authenticated addresses over unauthenticated DHT hearsay.
+func (p *PostgresDBService) HasVerifiedSignedPeerRecord(peerId string) bool {
+	var verified bool
+	err := p.db.QueryRowContext(context.Background(),
+		`SELECT spr_verified FROM signed_peer_record WHERE peer_id = $1`, peerId,
+	).Scan(&verified)
+	if err != nil {
+		return false
+	}
+	return verified
+}
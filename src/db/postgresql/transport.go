@@ -0,0 +1,29 @@
+package postgresql
+
+import "context"
+
+func init() {
+	schemaMigrations = append(schemaMigrations, peerTransportSchema)
+}
+
+const peerTransportSchema = `
+CREATE TABLE IF NOT EXISTS peer_transport (
+	peer_id TEXT PRIMARY KEY,
+	transport TEXT NOT NULL,
+	updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+`
+
+// StorePeerTransport records the transport (TCP/QUIC/WebSocket/circuit-relay)
+// a successful dial to peerId used; see hosts.TransportForMaddr, which
+// classifies the dialed multiaddr into one of these names.
+func (p *PostgresDBService) StorePeerTransport(peerId string, transport string) error {
+	_, err := p.db.ExecContext(context.Background(), `
+		INSERT INTO peer_transport (peer_id, transport, updated_at)
+		VALUES ($1, $2, now())
+		ON CONFLICT (peer_id) DO UPDATE SET
+			transport = EXCLUDED.transport,
+			updated_at = now()
+	`, peerId, transport)
+	return err
+}
@@ -18,8 +18,11 @@ import (
 	"github.com/migalabs/armiarma/src/db"
 	"github.com/migalabs/armiarma/src/hosts"
 	"github.com/migalabs/armiarma/src/info"
+	"github.com/migalabs/armiarma/src/metrics"
+	"github.com/migalabs/armiarma/src/telemetry"
 	"github.com/minio/sha256-simd"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var (
@@ -42,6 +45,28 @@ type GossipSub struct {
 	// map where the key are the topic names in string, and the values are the TopicSubscription
 	TopicArray     map[string]*TopicSubscription
 	MessageMetrics *MessageMetrics
+
+	// ScoreParams and ScoreThresholds drive go-libp2p-pubsub's peer scoring,
+	// seeded with Eth2 defaults. Per-topic params are set through each
+	// Topic's own SetScoreParams as it gets joined (see JoinAndSubscribe),
+	// never by writing ScoreParams.Topics directly, since that map is read
+	// concurrently by the scoring goroutine once PubsubService exists.
+	ScoreParams     *pubsub.PeerScoreParams
+	ScoreThresholds *pubsub.PeerScoreThresholds
+
+	// Telemetry is optional; when set, JoinAndSubscribe is wrapped in a
+	// trace span and topic mesh sizes are reported to it.
+	Telemetry *telemetry.Telemetry
+
+	// Metrics is optional; when set, newScoreInspector additionally
+	// snapshots each peer's overall gossipsub score into it, so scores
+	// end up exportable via metrics.PeerStore.ExportToCSV's Score column.
+	Metrics *metrics.PeerStore
+
+	// tracer is the RawTracer attached at construction, with or without
+	// tracerSinks; SetTelemetry forwards onto it so Graft/Prune
+	// can drive the MeshSize gauge.
+	tracer *rawTracer
 }
 
 // NewEmptyGossipSub:
@@ -58,11 +83,40 @@ func NewEmptyGossipSub() *GossipSub {
 // @param ctx: parent context for the gossip service.
 // @param h: the libp2p.PubSub topic of the joined topic.
 // @param peerstore: the peerstore where to sotre the data.
-// @param stdOpts: list of options to generate the base of the gossipsub service.
+// @param forkDigest: optional; only used as a non-nil/nil switch. When
+// non-nil, message IDs are computed with the post-Altair, domain-separated,
+// snappy-aware function (NewForkDigestMsgIDFunction), which does not
+// itself take a fork digest since the topic name already encodes it. When
+// nil, the legacy plain SHA-256-of-payload MsgIDFunction is used.
+// @param tracerSinks: optional sinks (e.g. NewJSONLFileSink, NewRingBufferSink)
+// that receive every raw protocol-level GossipSub event (Graft/Prune,
+// Reject/DuplicateMessage, ...). When none are given, a RawTracer is still
+// attached, but its events reach no sink.
 // @return: pointer to GossipSub struct.
-func NewGossipSub(ctx context.Context, h *hosts.BasicLibp2pHost, peerstore *db.PeerStore) *GossipSub {
+// Both forkDigest and tracerSinks are optional (nil / none is valid), so
+// existing callers built against the pre-forkDigest/pre-tracerSinks
+// signature only need `, nil` appended at the call site.
+func NewGossipSub(ctx context.Context, h *hosts.BasicLibp2pHost, peerstore *db.PeerStore, forkDigest ForkDigestProvider, tracerSinks ...TraceSink) *GossipSub {
 	mainCtx, cancel := context.WithCancel(ctx)
 
+	msgMetrics := NewMessageMetrics()
+	gs := &GossipSub{
+		ctx:             mainCtx,
+		cancel:          cancel,
+		InfoObj:         h.GetInfoObj(),
+		BasicHost:       h,
+		PeerStore:       peerstore,
+		TopicArray:      make(map[string]*TopicSubscription),
+		MessageMetrics:  &msgMetrics,
+		ScoreParams:     DefaultScoreParams(),
+		ScoreThresholds: DefaultScoreThresholds(),
+	}
+
+	msgIDFn := MsgIDFunction
+	if forkDigest != nil {
+		msgIDFn = NewForkDigestMsgIDFunction()
+	}
+
 	// define gossipsub option
 	// Signature is not used in Eth2, therefore it is needed
 	// to specify this options to false
@@ -70,24 +124,22 @@ func NewGossipSub(ctx context.Context, h *hosts.BasicLibp2pHost, peerstore *db.P
 	psOptions := []pubsub.Option{
 		pubsub.WithMessageSigning(false),
 		pubsub.WithStrictSignatureVerification(false),
-		pubsub.WithMessageIdFn(MsgIDFunction),
+		pubsub.WithMessageIdFn(msgIDFn),
+		pubsub.WithPeerScore(gs.ScoreParams, gs.ScoreThresholds),
+		pubsub.WithPeerScoreInspect(gs.newScoreInspector(), scoreInspectPeriod),
 	}
+	// Always attach a rawTracer, even with no sinks: SetTelemetry needs it
+	// in place to wire Graft/Prune into the MeshSize gauge, which is
+	// independent of whether any TraceSink was configured.
+	gs.tracer = newRawTracer(tracerSinks...)
+	psOptions = append(psOptions, pubsub.WithRawTracer(gs.tracer))
 	ps, err := pubsub.NewGossipSub(mainCtx, h.Host(), psOptions...)
 	if err != nil {
 		Log.Panic(err)
 	}
-	msgMetrics := NewMessageMetrics()
+	gs.PubsubService = ps
 	// return the GossipSub object
-	return &GossipSub{
-		ctx:            mainCtx,
-		cancel:         cancel,
-		InfoObj:        h.GetInfoObj(),
-		BasicHost:      h,
-		PeerStore:      peerstore,
-		PubsubService:  ps,
-		TopicArray:     make(map[string]*TopicSubscription),
-		MessageMetrics: &msgMetrics,
-	}
+	return gs
 }
 
 // WithMessageIdFn is an option to customize the way a message ID is computed for a pubsub message
@@ -104,14 +156,38 @@ func MsgIDFunction(pmsg *pubsub_pb.Message) string {
 // This method allows the GossipSub service to join and
 // subscribe to a topic.
 // @param topicName: name of the topic to subscribe.
+// @param scoreParams: optional per-topic score params overriding the Eth2
+// defaults (DefaultTopicScoreParams) for this particular topic.
 // @return: pointer to GossipSub struct.
-func (gs *GossipSub) JoinAndSubscribe(topicName string) {
+func (gs *GossipSub) JoinAndSubscribe(topicName string, scoreParams ...*pubsub.TopicScoreParams) {
+	if gs.Telemetry != nil {
+		// Span only; gs.ctx is the long-lived service context shared by
+		// every JoinAndSubscribe/MessageReadingLoop call and must never be
+		// reassigned to this call's derived (and later ended) span context.
+		_, span := gs.Telemetry.StartSpan(gs.ctx, "JoinAndSubscribe")
+		defer span.End()
+	}
+
+	topicScore := DefaultTopicScoreParams(topicName)
+	if len(scoreParams) > 0 && scoreParams[0] != nil {
+		topicScore = scoreParams[0]
+	}
+
 	// Join topic
 	topic, err := gs.PubsubService.Join(topicName)
 	if err != nil {
 		Log.Errorf("Could not join topic: %s", topicName)
 		Log.Errorf(err.Error())
 	}
+	// Set the topic's score params through the Topic itself: the scoring
+	// goroutine pubsub.NewGossipSub started is already reading
+	// gs.ScoreParams.Topics, so writing that map directly here would race
+	// against it. Topic.SetScoreParams is go-libp2p-pubsub's own
+	// thread-safe way to set per-topic params after construction.
+	if err := topic.SetScoreParams(topicScore); err != nil {
+		Log.Errorf("Could not set score params for topic: %s", topicName)
+		Log.Errorf(err.Error())
+	}
 	// Subscribe to the topic
 	sub, err := topic.Subscribe()
 	if err != nil {
@@ -128,6 +204,21 @@ func (gs *GossipSub) JoinAndSubscribe(topicName string) {
 	go gs.TopicArray[topicName].MessageReadingLoop(gs.BasicHost.Host(), gs.PeerStore)
 }
 
+// SetTelemetry attaches a telemetry.Telemetry instance, enabling tracing
+// spans around JoinAndSubscribe and mesh-size gauge reporting.
+func (gs *GossipSub) SetTelemetry(t *telemetry.Telemetry) {
+	gs.Telemetry = t
+	if gs.tracer != nil {
+		gs.tracer.setTelemetry(t)
+	}
+}
+
+// SetMetricsStore attaches a metrics.PeerStore so newScoreInspector can
+// snapshot gossipsub scores into it for CSV export.
+func (gs *GossipSub) SetMetricsStore(m *metrics.PeerStore) {
+	gs.Metrics = m
+}
+
 func (gs *GossipSub) Close() {
 	Log.Info("gossipsub close has been detected, closing dependant go-routines")
 	gs.cancel()
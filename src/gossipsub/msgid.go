@@ -0,0 +1,72 @@
+package gossipsub
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+
+	pubsub_pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/minio/sha256-simd"
+	"github.com/golang/snappy"
+)
+
+// Consensus-spec domain separation tags, as defined in the p2p-interface
+// spec for computing gossipsub message IDs.
+// See: https://github.com/ethereum/consensus-specs/blob/dev/specs/altair/p2p-interface.md#topics-and-messages
+var (
+	messageDomainValidSnappy   = [4]byte{0x01, 0x00, 0x00, 0x00}
+	messageDomainInvalidSnappy = [4]byte{0x00, 0x00, 0x00, 0x00}
+)
+
+// ForkDigestProvider returns the current fork digest used to compute
+// fork-dependent gossipsub message IDs. Implementations typically derive
+// it from genesis validators root + the currently active fork version,
+// updating as the chain transitions across forks (Altair, Bellatrix...).
+//
+// NewForkDigestMsgIDFunction does not actually take one: the spec's
+// message-id computation below is not fork-digest-dependent (the fork
+// digest is already baked into pmsg.GetTopic() by the topic name itself),
+// so there is nothing for a provider to feed into it. It is kept here as
+// the seam NewGossipSub switches on to decide whether to use this
+// function or the legacy one, not as an input to the hash.
+type ForkDigestProvider interface {
+	CurrentForkDigest() [4]byte
+}
+
+// NewForkDigestMsgIDFunction builds a pubsub.MsgIdFunction computing the
+// post-Altair Eth2 message ID: a domain-separated SHA-256 over
+// MESSAGE_DOMAIN_VALID_SNAPPY || topic_len || topic || uncompressed_data,
+// truncated to the spec's 20-byte message id, falling back to
+// MESSAGE_DOMAIN_INVALID_SNAPPY || snappy_payload when the payload fails
+// to decompress.
+func NewForkDigestMsgIDFunction() func(pmsg *pubsub_pb.Message) string {
+	return func(pmsg *pubsub_pb.Message) string {
+		topic := pmsg.GetTopic()
+
+		decompressed, err := snappy.Decode(nil, pmsg.Data)
+		if err != nil {
+			return messageID(messageDomainInvalidSnappy, nil, pmsg.Data)
+		}
+		return messageID(messageDomainValidSnappy, []byte(topic), decompressed)
+	}
+}
+
+// messageIDLength is MESSAGE_ID_LENGTH from the p2p-interface spec: the
+// message id is the first 20 bytes of the domain-separated SHA-256, not
+// the full 32-byte digest.
+const messageIDLength = 20
+
+// messageID hashes domain || topic_len (8-byte LE, only when topic != nil)
+// || topic || data, matching the consensus-spec message-id computation,
+// and truncates it to messageIDLength before encoding.
+func messageID(domain [4]byte, topic []byte, data []byte) string {
+	h := sha256.New()
+	h.Write(domain[:])
+	if topic != nil {
+		var topicLen [8]byte
+		binary.LittleEndian.PutUint64(topicLen[:], uint64(len(topic)))
+		h.Write(topicLen[:])
+		h.Write(topic)
+	}
+	h.Write(data)
+	return base64.URLEncoding.EncodeToString(h.Sum(nil)[:messageIDLength])
+}
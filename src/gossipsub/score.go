@@ -0,0 +1,103 @@
+package gossipsub
+
+import (
+	"math"
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+)
+
+// Eth2 gossipsub scoring defaults, following the parameters used by the
+// consensus-layer clients (mesh time quantum pegged to the 12s slot time,
+// mesh degree D=8). These are a starting point and can be overridden per
+// deployment through ScoreParams/ScoreThresholds on GossipSub.
+const (
+	eth2SlotDuration  = 12 * time.Second
+	eth2MeshD         = 8
+	eth2DecayInterval = eth2SlotDuration
+	eth2DecayToZero   = 0.01
+)
+
+// DefaultScoreParams returns the default PeerScoreParams applied to the
+// GossipSub service, with a per-topic score for every subscribed topic
+// populated via DefaultTopicScoreParams.
+func DefaultScoreParams() *pubsub.PeerScoreParams {
+	return &pubsub.PeerScoreParams{
+		Topics:        make(map[string]*pubsub.TopicScoreParams),
+		TopicScoreCap: 32.72,
+
+		AppSpecificScore: func(p string) float64 { return 0 },
+		AppSpecificWeight: 1,
+
+		// IP colocation: penalize many peers behind the same IP, as seen
+		// on Sybil-heavy testnets.
+		IPColocationFactorWeight:    -35.11,
+		IPColocationFactorThreshold: 10,
+
+		DecayInterval: eth2DecayInterval,
+		DecayToZero:   eth2DecayToZero,
+
+		BehaviourPenaltyWeight:    -15.92,
+		BehaviourPenaltyThreshold: 6,
+		BehaviourPenaltyDecay:     scoreDecay(10*eth2SlotDuration, eth2DecayInterval),
+	}
+}
+
+// DefaultTopicScoreParams returns reasonable Eth2 per-topic score params for
+// the given topic, tuned around a mesh degree of 8 and the 12s slot time.
+func DefaultTopicScoreParams(topic string) *pubsub.TopicScoreParams {
+	return &pubsub.TopicScoreParams{
+		TopicWeight: 0.5,
+
+		// P1: time in mesh, capped at ~1h worth of slots.
+		TimeInMeshWeight:  0.0324,
+		TimeInMeshQuantum: eth2DecayInterval,
+		TimeInMeshCap:     300,
+
+		// P2: first message deliveries, decaying over 20 slots (240s).
+		FirstMessageDeliveriesWeight: 0.128,
+		FirstMessageDeliveriesDecay:  scoreDecay(20*eth2SlotDuration, eth2DecayInterval),
+		FirstMessageDeliveriesCap:    300,
+
+		// P3: mesh message delivery rate, only relevant once the peer has
+		// been meshed for at least four slots.
+		MeshMessageDeliveriesWeight:     -0.064,
+		MeshMessageDeliveriesDecay:      scoreDecay(20*eth2SlotDuration, eth2DecayInterval),
+		MeshMessageDeliveriesCap:        float64(eth2MeshD) * 2,
+		MeshMessageDeliveriesThreshold:  float64(eth2MeshD) / 2,
+		MeshMessageDeliveriesWindow:     2 * time.Second,
+		MeshMessageDeliveriesActivation: 4 * eth2SlotDuration,
+
+		// P3b: sticky penalty applied when a peer is pruned while still
+		// under the P3 delivery threshold.
+		MeshFailurePenaltyWeight: -0.064,
+		MeshFailurePenaltyDecay:  scoreDecay(20*eth2SlotDuration, eth2DecayInterval),
+
+		// P4: invalid messages are penalized heavily and decay slowly.
+		InvalidMessageDeliveriesWeight: -99,
+		InvalidMessageDeliveriesDecay:  scoreDecay(50*eth2SlotDuration, eth2DecayInterval),
+	}
+}
+
+// DefaultScoreThresholds returns the default PeerScoreThresholds used to
+// gate graylisting/publishing/gossiping decisions.
+func DefaultScoreThresholds() *pubsub.PeerScoreThresholds {
+	return &pubsub.PeerScoreThresholds{
+		GossipThreshold:             -4000,
+		PublishThreshold:            -8000,
+		GraylistThreshold:           -16000,
+		AcceptPXThreshold:           100,
+		OpportunisticGraftThreshold: 5,
+	}
+}
+
+// scoreDecay computes the decay factor so that a value decays to
+// DecayToZero over the given duration, sampled every interval.
+// decay^ticks = DecayToZero  =>  decay = DecayToZero^(1/ticks)
+func scoreDecay(decay time.Duration, interval time.Duration) float64 {
+	ticks := float64(decay) / float64(interval)
+	if ticks <= 0 {
+		return 0
+	}
+	return math.Pow(eth2DecayToZero, 1/ticks)
+}
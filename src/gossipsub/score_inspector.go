@@ -0,0 +1,35 @@
+package gossipsub
+
+import (
+	"time"
+
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+)
+
+// scoreInspectPeriod sets how often the PeerScoreInspector snapshot runs.
+const scoreInspectPeriod = 10 * time.Second
+
+// newScoreInspector builds the pubsub.ExtendedPeerScoreInspectFn that gets
+// wired into the GossipSub service through pubsub.WithPeerScoreInspect.
+// On every invocation it stores each peer's per-topic score into the
+// PeerStore, so scores end up alongside the rest of the connection metrics
+// and are exportable via ExportToCSV. When Metrics is also set, the
+// peer's overall score is additionally snapshotted there, so it reaches
+// metrics.PeerStore.ExportToCSV's Score column too.
+func (gs *GossipSub) newScoreInspector() pubsub.ExtendedPeerScoreInspectFn {
+	return func(scores map[string]*pubsub.PeerScoreSnapshot) {
+		for peerID, snapshot := range scores {
+			for topic, topicSnapshot := range snapshot.Topics {
+				err := gs.PeerStore.ScoreEvent(peerID, topic, topicSnapshot.Score)
+				if err != nil {
+					Log.Debugf("could not store score for peer %s on topic %s: %s", peerID, topic, err.Error())
+				}
+			}
+			if gs.Metrics != nil {
+				if err := gs.Metrics.ScoreEvent(peerID, snapshot.Score); err != nil {
+					Log.Debugf("could not store overall score for peer %s: %s", peerID, err.Error())
+				}
+			}
+		}
+	}
+}
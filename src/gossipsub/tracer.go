@@ -0,0 +1,131 @@
+package gossipsub
+
+import (
+	"context"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	pubsub "github.com/libp2p/go-libp2p-pubsub"
+	pubsub_pb "github.com/libp2p/go-libp2p-pubsub/pb"
+	"github.com/migalabs/armiarma/src/telemetry"
+)
+
+// TraceEvent is a single protocol-level GossipSub event captured by the
+// RawTracer, carrying just enough context to reconstruct mesh health
+// (graft/prune churn, validation/delivery outcomes) offline.
+type TraceEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Type      string    `json:"type"`
+	PeerID    string    `json:"peer_id,omitempty"`
+	Topic     string    `json:"topic,omitempty"`
+	MsgID     string    `json:"msg_id,omitempty"`
+}
+
+// TraceSink receives every TraceEvent emitted by the rawTracer. Sinks must
+// be safe for concurrent use, since events arrive from the pubsub
+// internal goroutine.
+type TraceSink interface {
+	Write(ev TraceEvent)
+	Close() error
+}
+
+// rawTracer implements pubsub.RawTracer, translating every protocol-level
+// callback into a TraceEvent and forwarding it to the configured sinks.
+// It is attached through pubsub.WithRawTracer from NewGossipSub, and lives
+// alongside MessageMetrics: MessageMetrics keeps the subscription-loop
+// level counters, while rawTracer keeps the lower-level RPC churn that
+// MessageMetrics.MessageEvent never sees (graft/prune, rejections,
+// duplicates, throttling).
+type rawTracer struct {
+	sinks []TraceSink
+
+	// telemetry is optional; when set, Graft/Prune additionally drive the
+	// MeshSize gauge. See GossipSub.SetTelemetry.
+	telemetry *telemetry.Telemetry
+}
+
+// newRawTracer builds a rawTracer that fans every event out to the given
+// sinks.
+func newRawTracer(sinks ...TraceSink) *rawTracer {
+	return &rawTracer{sinks: sinks}
+}
+
+// setTelemetry attaches a telemetry.Telemetry instance, enabling the
+// MeshSize gauge on Graft/Prune.
+func (t *rawTracer) setTelemetry(tel *telemetry.Telemetry) {
+	t.telemetry = tel
+}
+
+func (t *rawTracer) emit(ev TraceEvent) {
+	ev.Timestamp = time.Now()
+	for _, sink := range t.sinks {
+		sink.Write(ev)
+	}
+}
+
+func (t *rawTracer) AddPeer(p peer.ID, proto protocol.ID) {
+	t.emit(TraceEvent{Type: "AddPeer", PeerID: p.String()})
+}
+
+func (t *rawTracer) RemovePeer(p peer.ID) {
+	t.emit(TraceEvent{Type: "RemovePeer", PeerID: p.String()})
+}
+
+func (t *rawTracer) Join(topic string) {
+	t.emit(TraceEvent{Type: "Join", Topic: topic})
+}
+
+func (t *rawTracer) Leave(topic string) {
+	t.emit(TraceEvent{Type: "Leave", Topic: topic})
+}
+
+func (t *rawTracer) Graft(p peer.ID, topic string) {
+	t.emit(TraceEvent{Type: "Graft", PeerID: p.String(), Topic: topic})
+	if t.telemetry != nil {
+		t.telemetry.SetMeshSize(context.Background(), topic, 1)
+	}
+}
+
+func (t *rawTracer) Prune(p peer.ID, topic string) {
+	t.emit(TraceEvent{Type: "Prune", PeerID: p.String(), Topic: topic})
+	if t.telemetry != nil {
+		t.telemetry.SetMeshSize(context.Background(), topic, -1)
+	}
+}
+
+func (t *rawTracer) ValidateMessage(msg *pubsub.Message) {
+	t.emit(TraceEvent{Type: "ValidateMessage", PeerID: msg.GetFrom().String(), Topic: msg.GetTopic(), MsgID: msg.ID})
+}
+
+func (t *rawTracer) DeliverMessage(msg *pubsub.Message) {
+	t.emit(TraceEvent{Type: "DeliverMessage", PeerID: msg.GetFrom().String(), Topic: msg.GetTopic(), MsgID: msg.ID})
+}
+
+func (t *rawTracer) RejectMessage(msg *pubsub.Message, reason string) {
+	t.emit(TraceEvent{Type: "RejectMessage:" + reason, PeerID: msg.GetFrom().String(), Topic: msg.GetTopic(), MsgID: msg.ID})
+}
+
+func (t *rawTracer) DuplicateMessage(msg *pubsub.Message) {
+	t.emit(TraceEvent{Type: "DuplicateMessage", PeerID: msg.GetFrom().String(), Topic: msg.GetTopic(), MsgID: msg.ID})
+}
+
+func (t *rawTracer) ThrottlePeer(p peer.ID) {
+	t.emit(TraceEvent{Type: "ThrottlePeer", PeerID: p.String()})
+}
+
+func (t *rawTracer) RecvRPC(rpc *pubsub_pb.RPC) {
+	t.emit(TraceEvent{Type: "RecvRPC"})
+}
+
+func (t *rawTracer) SendRPC(rpc *pubsub_pb.RPC, p peer.ID) {
+	t.emit(TraceEvent{Type: "SendRPC", PeerID: p.String()})
+}
+
+func (t *rawTracer) DropRPC(rpc *pubsub_pb.RPC, p peer.ID) {
+	t.emit(TraceEvent{Type: "DropRPC", PeerID: p.String()})
+}
+
+func (t *rawTracer) UndeliverableMessage(msg *pubsub.Message) {
+	t.emit(TraceEvent{Type: "UndeliverableMessage", Topic: msg.GetTopic(), MsgID: msg.ID})
+}
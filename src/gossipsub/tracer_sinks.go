@@ -0,0 +1,134 @@
+package gossipsub
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// jsonlFileSink writes every TraceEvent as a line of JSON to a file,
+// rotating to a new file once the current one reaches rotateSize bytes.
+type jsonlFileSink struct {
+	mu         sync.Mutex
+	dir        string
+	prefix     string
+	rotateSize int64
+
+	file    *os.File
+	written int64
+}
+
+// NewJSONLFileSink returns a TraceSink that rotates JSONL files under dir,
+// named "<prefix>-<unix-nano>.jsonl", once the active file passes
+// rotateSize bytes.
+func NewJSONLFileSink(dir string, prefix string, rotateSize int64) (TraceSink, error) {
+	s := &jsonlFileSink{dir: dir, prefix: prefix, rotateSize: rotateSize}
+	if err := s.rotate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *jsonlFileSink) rotate() error {
+	if s.file != nil {
+		s.file.Close()
+	}
+	path := fmt.Sprintf("%s/%s-%d.jsonl", s.dir, s.prefix, time.Now().UnixNano())
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	s.file = f
+	s.written = 0
+	return nil
+}
+
+func (s *jsonlFileSink) Write(ev TraceEvent) {
+	line, err := json.Marshal(ev)
+	if err != nil {
+		Log.Errorf("could not marshal trace event: %s", err.Error())
+		return
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.written >= s.rotateSize {
+		if err := s.rotate(); err != nil {
+			Log.Errorf("could not rotate trace file: %s", err.Error())
+			return
+		}
+	}
+	n, err := s.file.Write(line)
+	if err != nil {
+		Log.Errorf("could not write trace event: %s", err.Error())
+		return
+	}
+	s.written += int64(n)
+}
+
+func (s *jsonlFileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	return s.file.Close()
+}
+
+// ringBufferSink keeps the last `size` TraceEvents in memory and exposes
+// them over HTTP as a JSON array, for quickly inspecting mesh churn on a
+// live crawl without tailing a file.
+type ringBufferSink struct {
+	mu     sync.Mutex
+	events []TraceEvent
+	next   int
+	filled bool
+}
+
+// NewRingBufferSink returns a TraceSink holding the last `size` events in
+// memory, served as JSON on the given ServeMux path.
+func NewRingBufferSink(mux *http.ServeMux, path string, size int) TraceSink {
+	s := &ringBufferSink{events: make([]TraceEvent, size)}
+	mux.HandleFunc(path, s.handleHTTP)
+	return s
+}
+
+func (s *ringBufferSink) Write(ev TraceEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events[s.next] = ev
+	s.next = (s.next + 1) % len(s.events)
+	if s.next == 0 {
+		s.filled = true
+	}
+}
+
+// snapshot returns the buffered events in chronological order.
+func (s *ringBufferSink) snapshot() []TraceEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.filled {
+		out := make([]TraceEvent, s.next)
+		copy(out, s.events[:s.next])
+		return out
+	}
+	out := make([]TraceEvent, len(s.events))
+	copy(out, s.events[s.next:])
+	copy(out[len(s.events)-s.next:], s.events[:s.next])
+	return out
+}
+
+func (s *ringBufferSink) handleHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(s.snapshot()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *ringBufferSink) Close() error {
+	return nil
+}
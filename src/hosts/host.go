@@ -0,0 +1,158 @@
+/*
+Copyright © 2021 Miga Labs
+*/
+package hosts
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/libp2p/go-libp2p"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	quic "github.com/libp2p/go-libp2p-quic-transport"
+	circuit "github.com/libp2p/go-libp2p/p2p/protocol/circuitv2/client"
+	"github.com/libp2p/go-libp2p/p2p/transport/tcp"
+	libp2pws "github.com/libp2p/go-libp2p/p2p/transport/websocket"
+	libp2pwt "github.com/libp2p/go-libp2p/p2p/transport/webtransport"
+	ma "github.com/multiformats/go-multiaddr"
+
+	"github.com/migalabs/armiarma/src/config"
+	"github.com/migalabs/armiarma/src/db"
+	"github.com/migalabs/armiarma/src/info"
+	"github.com/migalabs/armiarma/src/utils/apis"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ModuleName = "HOST"
+	Log        = logrus.WithField(
+		"module", ModuleName,
+	)
+)
+
+// Transport name constants, recorded against a successfully dialed peer
+// so the resulting dataset can quantify transport adoption.
+const (
+	TransportTCP          = "tcp"
+	TransportQUIC         = "quic"
+	TransportWebSocket    = "ws"
+	TransportWebTransport = "webtransport"
+	TransportCircuitRelay = "circuit-relay"
+)
+
+// BasicLibp2pHost wraps the libp2p host used by the Filecoin crawler,
+// plus the transport set and relay reservations it was built with.
+type BasicLibp2pHost struct {
+	ctx context.Context
+
+	h          host.Host
+	transports config.Transports
+
+	ipLocalizer *apis.PeerLocalizer
+	db          *db.PeerStore
+}
+
+// NewBasicLibp2pFilecoin2Host builds the crawler's libp2p host. TCP is
+// always registered; QUIC, WebSocket and WebTransport are added on top of
+// it when enabled in transports, so peers that only announce
+// "/udp/.../quic" or "/wss" addresses (common among minimal libp2p node
+// setups) are no longer unreachable. When transports.RelayPeers is
+// non-empty, a circuit-relay v2 client reservation is requested on each
+// one after the host comes up, so the host stays dialable behind NAT
+// through "/p2p/<relay>/p2p-circuit/p2p/<host>".
+func NewBasicLibp2pFilecoin2Host(ctx context.Context, info info.InfoData, ipLocalizer *apis.PeerLocalizer, db *db.PeerStore, transports config.Transports) (*BasicLibp2pHost, error) {
+	opts := []libp2p.Option{
+		libp2p.ListenAddrStrings(info.GetTransportMaddrs()...),
+		libp2p.Transport(tcp.NewTCPTransport),
+	}
+
+	if transports.QUIC {
+		opts = append(opts, libp2p.Transport(quic.NewTransport))
+	}
+	if transports.WebSocket {
+		opts = append(opts, libp2p.Transport(libp2pws.New))
+	}
+	if transports.WebTransport {
+		opts = append(opts, libp2p.Transport(libp2pwt.New))
+	}
+	if len(transports.RelayPeers) > 0 {
+		opts = append(opts, libp2p.EnableRelay())
+	}
+
+	h, err := libp2p.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create libp2p host: %w", err)
+	}
+
+	bh := &BasicLibp2pHost{
+		ctx:         ctx,
+		h:           h,
+		transports:  transports,
+		ipLocalizer: ipLocalizer,
+		db:          db,
+	}
+
+	if len(transports.RelayPeers) > 0 {
+		go bh.reserveOnRelays(transports.RelayPeers)
+	}
+
+	return bh, nil
+}
+
+// Host returns the underlying libp2p host.
+func (bh *BasicLibp2pHost) Host() host.Host {
+	return bh.h
+}
+
+// Start kicks off anything the host needs running in the background;
+// currently a no-op placeholder kept for symmetry with the rest of the
+// crawler's Run() sequence, which always calls it.
+func (bh *BasicLibp2pHost) Start() {}
+
+// reserveOnRelays parses each configured relay multiaddr and asks the
+// circuit-relay v2 client for a reservation. Each relay is tried once; a
+// failed connect or reservation is logged and not retried.
+func (bh *BasicLibp2pHost) reserveOnRelays(relays []string) {
+	for _, relayAddr := range relays {
+		maddr, err := ma.NewMultiaddr(relayAddr)
+		if err != nil {
+			Log.Errorf("invalid relay multiaddr %q: %s", relayAddr, err.Error())
+			continue
+		}
+		relayInfo, err := peer.AddrInfoFromP2pAddr(maddr)
+		if err != nil {
+			Log.Errorf("invalid relay peer info %q: %s", relayAddr, err.Error())
+			continue
+		}
+		if err := bh.h.Connect(bh.ctx, *relayInfo); err != nil {
+			Log.Errorf("could not connect to relay %s: %s", relayInfo.ID, err.Error())
+			continue
+		}
+		if _, err := circuit.Reserve(bh.ctx, bh.h, *relayInfo); err != nil {
+			Log.Errorf("could not reserve slot on relay %s: %s", relayInfo.ID, err.Error())
+			continue
+		}
+		Log.Infof("reserved circuit-relay v2 slot on %s", relayInfo.ID)
+	}
+}
+
+// TransportForMaddr classifies maddr by the transport protocol it dials
+// over, so a successful connection can be attributed to TCP, QUIC,
+// WebSocket/WebTransport or a circuit-relay hop.
+func TransportForMaddr(maddr ma.Multiaddr) string {
+	protos := maddr.Protocols()
+	for _, p := range protos {
+		switch p.Name {
+		case "p2p-circuit":
+			return TransportCircuitRelay
+		case "webtransport":
+			return TransportWebTransport
+		case "ws", "wss":
+			return TransportWebSocket
+		case "quic", "quic-v1":
+			return TransportQUIC
+		}
+	}
+	return TransportTCP
+}
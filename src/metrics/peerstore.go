@@ -1,15 +1,19 @@
 package metrics
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"runtime"
+	"sync"
 	"time"
 
+	"github.com/migalabs/armiarma/src/telemetry"
 	"github.com/pkg/errors"
 	"github.com/protolambda/rumor/metrics/utils"
 	"github.com/protolambda/rumor/p2p/gossip/database"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type ErrorHandling func(*Peer)
@@ -20,9 +24,22 @@ type PeerStore struct {
 	StartTime         time.Time
 	PeerstoreIterTime time.Duration
 	MsgNotChannels    map[string](chan bool) // TODO: Unused?
+	Reputation        *ReputationBook
+	// Telemetry is optional; when set, connection-attempt latency and
+	// peerstore iteration time are additionally reported as live metrics
+	// instead of only through ExportToCSV.
+	Telemetry *telemetry.Telemetry
+
+	scoresMu sync.Mutex
+	scores   map[string]float64
+}
+
+// SetTelemetry attaches a telemetry.Telemetry instance to the PeerStore.
+func (c *PeerStore) SetTelemetry(t *telemetry.Telemetry) {
+	c.Telemetry = t
 }
 
-func NewPeerStore(dbtype string, path string) PeerStore {
+func NewPeerStore(ctx context.Context, dbtype string, path string) PeerStore {
 	var db PeerStoreStorage
 	switch dbtype {
 	case "bold":
@@ -42,10 +59,34 @@ func NewPeerStore(dbtype string, path string) PeerStore {
 		PeerStore:      db,
 		StartTime:      time.Now(),
 		MsgNotChannels: make(map[string](chan bool)),
+		Reputation:     NewReputationBook(ctx),
+		scores:         make(map[string]float64),
 	}
 	return ps
 }
 
+// ScoreEvent records a gossipsub peer score snapshot for peerId, read
+// back out by ExportToCSV's Score column. See
+// gossipsub.GossipSub.newScoreInspector, the only caller.
+func (c *PeerStore) ScoreEvent(peerId string, score float64) error {
+	c.scoresMu.Lock()
+	defer c.scoresMu.Unlock()
+	c.scores[peerId] = score
+	return nil
+}
+
+func (c *PeerStore) score(peerId string) float64 {
+	c.scoresMu.Lock()
+	defer c.scoresMu.Unlock()
+	return c.scores[peerId]
+}
+
+// ReportPeer records a ReputationChange against peerId, accumulating it
+// into the peer's reputation score. See ReputationBook.ReportPeer.
+func (c *PeerStore) ReportPeer(peerId string, change ReputationChange) int32 {
+	return c.Reputation.ReportPeer(peerId, change)
+}
+
 func (c *PeerStore) ImportPeerStoreMetrics(importFolder string) error {
 	// TODO: Load to memory an existing csv
 	// Perhaps not needed since we are migrating to a database
@@ -111,6 +152,9 @@ func (c *PeerStore) GetPeerData(peerId string) (Peer, error) {
 /// AddNewAttempts adds the resuts of a negative new attempt over an existing peer
 // increasing the attempt counter and the respective fields
 func (c *PeerStore) AddNewNegConnectionAttempt(id string, rec_err string, fn ErrorHandling) error {
+	if c.Reputation.IsBanned(id) {
+		return fmt.Errorf("peer %s is backed off due to low reputation, skipping dial", id)
+	}
 	p, err := c.GetPeerData(id)
 	if err != nil { // the peer was already in the sync.Map return true
 		return fmt.Errorf("Not peer found with that ID %s", id)
@@ -127,6 +171,7 @@ func (c *PeerStore) AddNewNegConnectionAttempt(id string, rec_err string, fn Err
 
 	// Store the new struct in the sync.Map
 	c.StorePeer(p)
+	c.Reputation.ReportPeer(id, BadHandshake)
 	return nil
 }
 
@@ -149,6 +194,7 @@ func (c *PeerStore) AddNewPosConnectionAttempt(id string) error {
 	p.AddPositiveConnAttempt()
 	// Store the new struct in the sync.Map
 	c.StorePeer(p)
+	c.Reputation.ReportPeer(id, GoodResponse)
 	return nil
 }
 
@@ -160,6 +206,9 @@ func (c *PeerStore) ConnectionEvent(peerId string, direction string) error {
 	}
 	peer.ConnectionEvent(direction, time.Now())
 	c.StorePeer(peer)
+	if c.Telemetry != nil {
+		c.Telemetry.SetConnectedPeers(context.Background(), 1)
+	}
 	return nil
 }
 
@@ -171,6 +220,9 @@ func (c *PeerStore) DisconnectionEvent(peerId string) error {
 	}
 	peer.DisconnectionEvent(time.Now())
 	c.StorePeer(peer)
+	if c.Telemetry != nil {
+		c.Telemetry.SetConnectedPeers(context.Background(), -1)
+	}
 	return nil
 }
 
@@ -191,24 +243,48 @@ func (c *PeerStore) MetadataEvent(peerId string, success bool) error {
 // AddNewAttempts adds the resuts of a new attempt over an existing peer
 // increasing the attempt counter and the respective fields
 func (c *PeerStore) ConnectionAttemptEvent(peerId string, succeed bool, conErr string) error {
+	start := time.Now()
+	ctx := context.Background()
+	if c.Telemetry != nil {
+		var span trace.Span
+		ctx, span = c.Telemetry.StartSpan(ctx, "ConnectionAttemptEvent")
+		defer span.End()
+	}
 	peer, err := c.GetPeerData(peerId)
 	if err != nil {
 		return errors.New("could not add connection attempt, peer is not in the list: " + peerId)
 	}
 	peer.ConnectionAttemptEvent(succeed, conErr)
 	c.StorePeer(peer)
+	if succeed {
+		c.Reputation.ReportPeer(peerId, GoodResponse)
+	} else {
+		c.Reputation.ReportPeer(peerId, BadHandshake)
+	}
+	if c.Telemetry != nil {
+		c.Telemetry.RecordConnectionAttempt(ctx, time.Since(start), succeed)
+	}
 	return nil
 }
 
 // Function that Manages the metrics updates for the incoming messages
 // TODO: Rename to AddNewMessageEvent or something like that
 func (c *PeerStore) MessageEvent(peerId string, topicName string) error {
+	ctx := context.Background()
+	if c.Telemetry != nil {
+		var span trace.Span
+		ctx, span = c.Telemetry.StartSpan(ctx, "MessageEvent")
+		defer span.End()
+	}
 	peer, err := c.GetPeerData(peerId)
 	if err != nil {
 		return errors.New("could not add message event, peer is not in the list: " + peerId)
 	}
 	peer.MessageEvent(topicName, time.Now())
 	c.StorePeer(peer)
+	if c.Telemetry != nil {
+		c.Telemetry.RecordMessage(ctx, topicName, peerId)
+	}
 	return nil
 }
 
@@ -226,6 +302,9 @@ func (gm *PeerStore) GetErrorCounter() map[string]uint64 {
 // Update the last iteration throught whole PeerStore
 func (c *PeerStore) NewPeerstoreIteration(t time.Duration) {
 	c.PeerstoreIterTime = t
+	if c.Telemetry != nil {
+		c.Telemetry.RecordPeerstoreIteration(context.Background(), t)
+	}
 }
 
 // Exports to a csv, useful for debug
@@ -238,14 +317,26 @@ func (c *PeerStore) ExportToCSV(filePath string) error {
 	defer csvFile.Close()
 
 	// First raw of the file will be the Titles of the columns
-	_, err = csvFile.WriteString("Peer Id,Node Id,User Agent,Client,Version,Pubkey,Address,Ip,Country,City,Request Metadata,Success Metadata,Attempted,Succeed,ConnStablished,IsConnected,Attempts,Error,Latency,Connections,Disconnections,Connected Time,Beacon Blocks,Beacon Aggregations,Voluntary Exits,Proposer Slashings,Attester Slashings,Total Messages\n")
+	header := "Peer Id,Node Id,User Agent,Client,Version,Pubkey,Address,Ip,Country,City,Request Metadata,Success Metadata,Attempted,Succeed,ConnStablished,IsConnected,Attempts,Error,Latency,Connections,Disconnections,Connected Time,Beacon Blocks,Beacon Aggregations,Voluntary Exits,Proposer Slashings,Attester Slashings,Total Messages,Reputation"
+	for _, reason := range reputationReasons {
+		header += "," + reason
+	}
+	header += ",Score"
+	_, err = csvFile.WriteString(header + "\n")
 	if err != nil {
 		errors.Wrap(err, "error while writing the titles on the csv "+filePath)
 	}
 
 	err = nil
 	c.PeerStore.Range(func(key string, value Peer) bool {
-		_, err = csvFile.WriteString(value.ToCsvLine())
+		line := value.ToCsvLine()
+		line = line[:len(line)-1] + fmt.Sprintf(",%d", c.Reputation.Reputation(key))
+		counters := c.Reputation.ReasonCounters(key)
+		for _, reason := range reputationReasons {
+			line += fmt.Sprintf(",%d", counters[reason])
+		}
+		line += fmt.Sprintf(",%f\n", c.score(key))
+		_, err = csvFile.WriteString(line)
 		return true
 	})
 
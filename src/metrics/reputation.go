@@ -0,0 +1,168 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ReputationChange is a single, signed adjustment to a peer's reputation,
+// modeled on the peerset/reputation approach used by Substrate-family
+// crawlers: every observed misbehavior (or good behavior) reports a value
+// and a human-readable reason, and reputations accumulate and decay over
+// time rather than being a single sticky Error string.
+type ReputationChange struct {
+	Value  int32
+	Reason string
+}
+
+// Predefined reputation changes for the misbehaviors/successes already
+// visible elsewhere in the crawler.
+var (
+	BadHandshake     = ReputationChange{Value: -2000, Reason: "bad handshake"}
+	BadBlock         = ReputationChange{Value: -5000, Reason: "bad block"}
+	DuplicateMessage = ReputationChange{Value: -1, Reason: "duplicate message"}
+	InvalidGossip    = ReputationChange{Value: -3000, Reason: "invalid gossip message"}
+	Timeout          = ReputationChange{Value: -500, Reason: "timeout"}
+	GoodResponse     = ReputationChange{Value: 100, Reason: "good response"}
+)
+
+// reputationReasons lists every predefined ReputationChange reason in a
+// stable order, so ExportToCSV can emit one counter column per reason
+// instead of a single aggregated reputation value.
+var reputationReasons = []string{
+	BadHandshake.Reason,
+	BadBlock.Reason,
+	DuplicateMessage.Reason,
+	InvalidGossip.Reason,
+	Timeout.Reason,
+	GoodResponse.Reason,
+}
+
+const (
+	// reputationBanThreshold is the default value below which a peer is
+	// considered misbehaving enough to back off re-dialing it.
+	reputationBanThreshold = int32(-5000)
+
+	// reputationBanDuration is how long a peer stays below threshold
+	// before AddNewNegConnectionAttempt will consider dialing it again.
+	reputationBanDuration = 1 * time.Hour
+
+	// reputationDecayInterval drives the
+	// background decay goroutine: every tick, every peer's reputation is
+	// halved, so transient bad behavior is eventually forgiven.
+	reputationDecayInterval = 10 * time.Minute
+)
+
+// reputationRecord is the per-peer reputation bookkeeping kept alongside
+// the PeerStore, separate from the Peer struct stored in PeerStoreStorage
+// so existing storage backends (bolt/memory) don't need to change shape.
+type reputationRecord struct {
+	value       int32
+	reasonCount map[string]uint64
+	bannedUntil time.Time
+}
+
+// ReputationBook tracks the accumulated ReputationChange per peer, with
+// exponential decay applied in the background so a peer can work its way
+// back into good standing.
+type ReputationBook struct {
+	mu        sync.Mutex
+	threshold int32
+	records   map[string]*reputationRecord
+}
+
+// NewReputationBook builds a ReputationBook using reputationBanThreshold
+// as the default ban threshold, and starts the background decay loop.
+func NewReputationBook(ctx context.Context) *ReputationBook {
+	rb := &ReputationBook{
+		threshold: reputationBanThreshold,
+		records:   make(map[string]*reputationRecord),
+	}
+	go rb.decayLoop(ctx)
+	return rb
+}
+
+func (rb *ReputationBook) decayLoop(ctx context.Context) {
+	ticker := time.NewTicker(reputationDecayInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			rb.decay()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (rb *ReputationBook) decay() {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	for _, rec := range rb.records {
+		rec.value /= 2
+	}
+}
+
+// ReportPeer accumulates change into peerId's reputation, tracking a
+// per-reason counter and, if the score drops below the configured
+// threshold, setting a back-off window during which the peer should not
+// be re-dialed.
+func (rb *ReputationBook) ReportPeer(peerId string, change ReputationChange) int32 {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	rec, ok := rb.records[peerId]
+	if !ok {
+		rec = &reputationRecord{reasonCount: make(map[string]uint64)}
+		rb.records[peerId] = rec
+	}
+	rec.value += change.Value
+	rec.reasonCount[change.Reason]++
+
+	if rec.value < rb.threshold {
+		rec.bannedUntil = time.Now().Add(reputationBanDuration)
+		log.Debugf("peer %s reputation %d below threshold %d, backing off until %s", peerId, rec.value, rb.threshold, rec.bannedUntil)
+	}
+	return rec.value
+}
+
+// IsBanned reports whether peerId is currently inside its back-off window.
+func (rb *ReputationBook) IsBanned(peerId string) bool {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	rec, ok := rb.records[peerId]
+	if !ok {
+		return false
+	}
+	return time.Now().Before(rec.bannedUntil)
+}
+
+// Reputation returns the current accumulated reputation value for peerId.
+func (rb *ReputationBook) Reputation(peerId string) int32 {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	rec, ok := rb.records[peerId]
+	if !ok {
+		return 0
+	}
+	return rec.value
+}
+
+// ReasonCounters returns a copy of the per-reason misbehavior counters for
+// peerId, for exporting alongside the reputation value.
+func (rb *ReputationBook) ReasonCounters(peerId string) map[string]uint64 {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	rec, ok := rb.records[peerId]
+	if !ok {
+		return nil
+	}
+	out := make(map[string]uint64, len(rec.reasonCount))
+	for reason, count := range rec.reasonCount {
+		out[reason] = count
+	}
+	return out
+}
@@ -0,0 +1,175 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/migalabs/armiarma/src/onchaindata/eth2/endpoint/types"
+	"github.com/pkg/errors"
+)
+
+// BeaconClient abstracts the on-chain data calls the crawler needs from a
+// consensus-layer node. InfuraClient was the only implementation, which
+// locked the crawler to Infura's Eth2 API; StandardBeaconClient below talks
+// the standard `/eth/v1/beacon/*` Beacon-API surface shared by Lighthouse,
+// Prysm, Teku, Nimbus and Lodestar, so any of them can be used instead.
+type BeaconClient interface {
+	Genesis(ctx context.Context) (types.Genesis, error)
+	Fork(ctx context.Context, stateID string) (types.Fork, error)
+	Finality(ctx context.Context) (types.Finality, error)
+	ValidatorSet(ctx context.Context, stateID string) (types.ValidatorSet, error)
+}
+
+// Standard Beacon-API endpoints, common to every client implementing the
+// spec at https://ethereum.github.io/beacon-APIs/.
+const (
+	standardGenesisEndpoint     = "/eth/v1/beacon/genesis"
+	standardForkEndpointFmt     = "/eth/v1/beacon/states/%s/fork"
+	standardFinalityEndpointFmt = "/eth/v1/beacon/states/%s/finality_checkpoints"
+	standardValidatorsEndpoints = "/eth/v1/beacon/states/%s/validators"
+)
+
+// StandardBeaconClient is a BeaconClient for any node implementing the
+// standard Beacon-API, optionally authenticated with a bearer token or
+// basic-auth credentials.
+type StandardBeaconClient struct {
+	*HttpClient
+}
+
+var _ BeaconClient = (*StandardBeaconClient)(nil)
+
+// NewStandardBeaconClient builds a StandardBeaconClient pointed at the
+// given Beacon-API base URL. Use the auth helpers on HttpClient (set via
+// opts) to configure a bearer token or basic-auth header.
+func NewStandardBeaconClient(baseURL string, opts ...HttpClientOption) *StandardBeaconClient {
+	return &StandardBeaconClient{HttpClient: NewHttpClient(baseURL, opts...)}
+}
+
+// NewLighthouseClient, NewPrysmClient, NewTekuClient, NewNimbusClient and
+// NewLodestarClient are named constructors for the clients most commonly
+// pointed at by operators. They all speak the exact same Beacon-API
+// surface, so they're thin aliases over StandardBeaconClient kept around
+// for readability in crawler configs and logs.
+func NewLighthouseClient(baseURL string, opts ...HttpClientOption) *StandardBeaconClient {
+	return NewStandardBeaconClient(baseURL, opts...)
+}
+
+func NewPrysmClient(baseURL string, opts ...HttpClientOption) *StandardBeaconClient {
+	return NewStandardBeaconClient(baseURL, opts...)
+}
+
+func NewTekuClient(baseURL string, opts ...HttpClientOption) *StandardBeaconClient {
+	return NewStandardBeaconClient(baseURL, opts...)
+}
+
+func NewNimbusClient(baseURL string, opts ...HttpClientOption) *StandardBeaconClient {
+	return NewStandardBeaconClient(baseURL, opts...)
+}
+
+func NewLodestarClient(baseURL string, opts ...HttpClientOption) *StandardBeaconClient {
+	return NewStandardBeaconClient(baseURL, opts...)
+}
+
+func (c *StandardBeaconClient) Genesis(ctx context.Context) (gen types.Genesis, err error) {
+	err = c.Get(ctx, standardGenesisEndpoint, &gen)
+	return gen, err
+}
+
+func (c *StandardBeaconClient) Fork(ctx context.Context, stateID string) (fork types.Fork, err error) {
+	err = c.Get(ctx, fmt.Sprintf(standardForkEndpointFmt, stateID), &fork)
+	return fork, err
+}
+
+func (c *StandardBeaconClient) Finality(ctx context.Context) (fin types.Finality, err error) {
+	err = c.Get(ctx, fmt.Sprintf(standardFinalityEndpointFmt, "head"), &fin)
+	return fin, err
+}
+
+func (c *StandardBeaconClient) ValidatorSet(ctx context.Context, stateID string) (vs types.ValidatorSet, err error) {
+	err = c.Get(ctx, fmt.Sprintf(standardValidatorsEndpoints, stateID), &vs)
+	return vs, err
+}
+
+// FailoverClient wraps a list of BeaconClients and transparently retries
+// the next one when the current endpoint errors, so a crawler config with
+// several Beacon-API endpoints keeps working as long as one of them is up.
+type FailoverClient struct {
+	clients []BeaconClient
+
+	mu   sync.Mutex
+	last int
+}
+
+// NewFailoverClient builds a FailoverClient trying each client in order on
+// every call, starting from the first one that last succeeded.
+func NewFailoverClient(clients ...BeaconClient) (*FailoverClient, error) {
+	if len(clients) == 0 {
+		return nil, errors.New("failover client needs at least one beacon client")
+	}
+	return &FailoverClient{clients: clients}, nil
+}
+
+// order returns f.clients rotated so the client that last succeeded is
+// tried first, rather than always restarting from index 0.
+func (f *FailoverClient) order() []BeaconClient {
+	f.mu.Lock()
+	start := f.last
+	f.mu.Unlock()
+	ordered := make([]BeaconClient, 0, len(f.clients))
+	ordered = append(ordered, f.clients[start:]...)
+	ordered = append(ordered, f.clients[:start]...)
+	return ordered
+}
+
+// markSucceeded remembers client as the one to try first next time.
+func (f *FailoverClient) markSucceeded(client BeaconClient) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, c := range f.clients {
+		if c == client {
+			f.last = i
+			return
+		}
+	}
+}
+
+func (f *FailoverClient) Genesis(ctx context.Context) (gen types.Genesis, err error) {
+	for _, c := range f.order() {
+		if gen, err = c.Genesis(ctx); err == nil {
+			f.markSucceeded(c)
+			return gen, nil
+		}
+	}
+	return gen, errors.Wrap(err, "all beacon endpoints failed requesting genesis")
+}
+
+func (f *FailoverClient) Fork(ctx context.Context, stateID string) (fork types.Fork, err error) {
+	for _, c := range f.order() {
+		if fork, err = c.Fork(ctx, stateID); err == nil {
+			f.markSucceeded(c)
+			return fork, nil
+		}
+	}
+	return fork, errors.Wrap(err, "all beacon endpoints failed requesting fork")
+}
+
+func (f *FailoverClient) Finality(ctx context.Context) (fin types.Finality, err error) {
+	for _, c := range f.order() {
+		if fin, err = c.Finality(ctx); err == nil {
+			f.markSucceeded(c)
+			return fin, nil
+		}
+	}
+	return fin, errors.Wrap(err, "all beacon endpoints failed requesting finality")
+}
+
+func (f *FailoverClient) ValidatorSet(ctx context.Context, stateID string) (vs types.ValidatorSet, err error) {
+	for _, c := range f.order() {
+		if vs, err = c.ValidatorSet(ctx, stateID); err == nil {
+			f.markSucceeded(c)
+			return vs, nil
+		}
+	}
+	return vs, errors.Wrap(err, "all beacon endpoints failed requesting validator set")
+}
@@ -0,0 +1,82 @@
+package endpoint
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// HttpClient is a small wrapper around net/http used by the BeaconClient
+// implementations to talk to a Beacon-API endpoint, optionally
+// authenticated with a bearer token or HTTP basic-auth, as configured by
+// the crawler's endpoint list.
+type HttpClient struct {
+	baseURL string
+	client  *http.Client
+
+	bearerToken       string
+	basicAuthUser     string
+	basicAuthPassword string
+}
+
+// HttpClientOption configures an HttpClient at construction time.
+type HttpClientOption func(*HttpClient)
+
+// WithBearerToken authenticates every request with an
+// "Authorization: Bearer <token>" header.
+func WithBearerToken(token string) HttpClientOption {
+	return func(c *HttpClient) {
+		c.bearerToken = token
+	}
+}
+
+// WithBasicAuth authenticates every request with HTTP basic-auth.
+func WithBasicAuth(user, password string) HttpClientOption {
+	return func(c *HttpClient) {
+		c.basicAuthUser = user
+		c.basicAuthPassword = password
+	}
+}
+
+// NewHttpClient builds an HttpClient pointed at baseURL.
+func NewHttpClient(baseURL string, opts ...HttpClientOption) *HttpClient {
+	c := &HttpClient{
+		baseURL: baseURL,
+		client:  http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Get issues a GET request against baseURL+path, decoding the JSON
+// response body into dst.
+func (c *HttpClient) Get(ctx context.Context, path string, dst interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return errors.Wrap(err, "building request to "+path)
+	}
+	if c.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.bearerToken)
+	}
+	if c.basicAuthUser != "" {
+		req.SetBasicAuth(c.basicAuthUser, c.basicAuthPassword)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "requesting "+path)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("unexpected status %d from %s", resp.StatusCode, path)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(dst); err != nil {
+		return errors.Wrap(err, "decoding response from "+path)
+	}
+	return nil
+}
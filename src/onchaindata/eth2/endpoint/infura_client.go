@@ -0,0 +1,59 @@
+package endpoint
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/migalabs/armiarma/src/onchaindata/eth2/endpoint/types"
+)
+
+// GENESIS_ENPOINT is the Beacon-API path ReqGenesis (genesis_call.go)
+// requests; Infura's hosted Eth2 API speaks the same standard Beacon-API
+// surface as StandardBeaconClient.
+const GENESIS_ENPOINT = standardGenesisEndpoint
+
+// InfuraClient is a BeaconClient talking to Infura's hosted Eth2 Beacon
+// API, authenticated through the HttpClientOptions passed to
+// NewInfuraClient (WithBearerToken or WithBasicAuth).
+type InfuraClient struct {
+	*HttpClient
+}
+
+var _ BeaconClient = (*InfuraClient)(nil)
+
+// NewInfuraClient builds an InfuraClient pointed at baseURL.
+func NewInfuraClient(baseURL string, opts ...HttpClientOption) *InfuraClient {
+	return &InfuraClient{HttpClient: NewHttpClient(baseURL, opts...)}
+}
+
+// IsInitialized reports whether the client has an HttpClient to issue
+// requests through.
+func (c *InfuraClient) IsInitialized() bool {
+	return c != nil && c.HttpClient != nil
+}
+
+// NewHttpsRequest issues path against the client's HttpClient, decoding
+// the JSON response into dst; see ReqGenesis in genesis_call.go.
+func (c *InfuraClient) NewHttpsRequest(ctx context.Context, path string, dst interface{}) error {
+	return c.Get(ctx, path, dst)
+}
+
+// Genesis satisfies BeaconClient by delegating to ReqGenesis.
+func (c *InfuraClient) Genesis(ctx context.Context) (types.Genesis, error) {
+	return c.ReqGenesis(ctx)
+}
+
+func (c *InfuraClient) Fork(ctx context.Context, stateID string) (fork types.Fork, err error) {
+	err = c.NewHttpsRequest(ctx, fmt.Sprintf(standardForkEndpointFmt, stateID), &fork)
+	return fork, err
+}
+
+func (c *InfuraClient) Finality(ctx context.Context) (fin types.Finality, err error) {
+	err = c.NewHttpsRequest(ctx, fmt.Sprintf(standardFinalityEndpointFmt, "head"), &fin)
+	return fin, err
+}
+
+func (c *InfuraClient) ValidatorSet(ctx context.Context, stateID string) (vs types.ValidatorSet, err error) {
+	err = c.NewHttpsRequest(ctx, fmt.Sprintf(standardValidatorsEndpoints, stateID), &vs)
+	return vs, err
+}
@@ -0,0 +1,64 @@
+// Package types holds the JSON response shapes returned by the
+// Beacon-API endpoints BeaconClient implementations call, decoded
+// straight off the wire by HttpClient.Get.
+package types
+
+// Genesis is the response body of GET /eth/v1/beacon/genesis.
+type Genesis struct {
+	Data struct {
+		GenesisTime           string `json:"genesis_time"`
+		GenesisValidatorsRoot string `json:"genesis_validators_root"`
+		GenesisForkVersion    string `json:"genesis_fork_version"`
+	} `json:"data"`
+}
+
+// Fork is the response body of GET /eth/v1/beacon/states/{state_id}/fork.
+type Fork struct {
+	Data struct {
+		PreviousVersion string `json:"previous_version"`
+		CurrentVersion  string `json:"current_version"`
+		Epoch           string `json:"epoch"`
+	} `json:"data"`
+}
+
+// Finality is the response body of
+// GET /eth/v1/beacon/states/{state_id}/finality_checkpoints.
+type Finality struct {
+	Data struct {
+		PreviousJustified struct {
+			Epoch string `json:"epoch"`
+			Root  string `json:"root"`
+		} `json:"previous_justified"`
+		CurrentJustified struct {
+			Epoch string `json:"epoch"`
+			Root  string `json:"root"`
+		} `json:"current_justified"`
+		Finalized struct {
+			Epoch string `json:"epoch"`
+			Root  string `json:"root"`
+		} `json:"finalized"`
+	} `json:"data"`
+}
+
+// ValidatorSet is the response body of
+// GET /eth/v1/beacon/states/{state_id}/validators.
+type ValidatorSet struct {
+	Data []Validator `json:"data"`
+}
+
+// Validator is a single entry of ValidatorSet.Data.
+type Validator struct {
+	Index     string `json:"index"`
+	Balance   string `json:"balance"`
+	Status    string `json:"status"`
+	Validator struct {
+		Pubkey                     string `json:"pubkey"`
+		WithdrawalCredentials      string `json:"withdrawal_credentials"`
+		EffectiveBalance           string `json:"effective_balance"`
+		Slashed                    bool   `json:"slashed"`
+		ActivationEligibilityEpoch string `json:"activation_eligibility_epoch"`
+		ActivationEpoch            string `json:"activation_epoch"`
+		ExitEpoch                  string `json:"exit_epoch"`
+		WithdrawableEpoch          string `json:"withdrawable_epoch"`
+	} `json:"validator"`
+}
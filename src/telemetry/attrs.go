@@ -0,0 +1,15 @@
+package telemetry
+
+import "go.opentelemetry.io/otel/attribute"
+
+func attrTopic(topic string) attribute.KeyValue {
+	return attribute.String("topic", topic)
+}
+
+func attrPeer(peerID string) attribute.KeyValue {
+	return attribute.String("peer_id", peerID)
+}
+
+func attrSucceed(succeed bool) attribute.KeyValue {
+	return attribute.Bool("succeed", succeed)
+}
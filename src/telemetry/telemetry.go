@@ -0,0 +1,200 @@
+/**
+This package wires OpenTelemetry meters and tracers into the crawler's
+existing metrics types (PeerStore, GossipSub), so a running crawl can be
+observed live instead of only through the post-mortem CSV export.
+MessageMetrics reports nothing to it.
+*/
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	otelprometheus "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	ModuleName = "TELEMETRY"
+	Log        = logrus.WithField("module", ModuleName)
+)
+
+// Config configures where the crawler exposes/exports its telemetry.
+type Config struct {
+	// PrometheusAddr, when non-empty, serves "/metrics" on this address
+	// (e.g. ":9090").
+	PrometheusAddr string
+	// OTLPEndpoint, when non-empty, additionally pushes metrics and traces
+	// to an OTLP collector at this endpoint.
+	OTLPEndpoint string
+	// OTLPProtocol selects the OTLP transport: "grpc" (default) or "http".
+	OTLPProtocol string
+}
+
+// Telemetry bundles the meters/tracer and the instruments fed by
+// PeerStore and GossipSub.
+type Telemetry struct {
+	meter  metric.Meter
+	tracer trace.Tracer
+
+	MessagesPerTopic         metric.Int64Counter
+	MessagesPerPeer          metric.Int64Counter
+	ConnectionAttemptLatency metric.Float64Histogram
+	PeerstoreIterTime        metric.Float64Histogram
+	ConnectedPeers           metric.Int64UpDownCounter
+	MeshSize                 metric.Int64UpDownCounter
+
+	server *http.Server
+}
+
+// NewTelemetry sets up the OTel SDK (Prometheus + optional OTLP exporters)
+// and the instruments used across the crawler.
+func NewTelemetry(ctx context.Context, cfg Config) (*Telemetry, error) {
+	meterProvider, server, err := newMeterProvider(cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "setting up meter provider")
+	}
+	tracerProvider, err := newTracerProvider(ctx, cfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "setting up tracer provider")
+	}
+	otel.SetTracerProvider(tracerProvider)
+
+	meter := meterProvider.Meter("armiarma/crawler")
+	t := &Telemetry{
+		meter:  meter,
+		tracer: tracerProvider.Tracer("armiarma/crawler"),
+		server: server,
+	}
+
+	if t.MessagesPerTopic, err = meter.Int64Counter("gossipsub_messages_per_topic_total"); err != nil {
+		return nil, err
+	}
+	if t.MessagesPerPeer, err = meter.Int64Counter("gossipsub_messages_per_peer_total"); err != nil {
+		return nil, err
+	}
+	if t.ConnectionAttemptLatency, err = meter.Float64Histogram("crawler_connection_attempt_latency_seconds"); err != nil {
+		return nil, err
+	}
+	if t.PeerstoreIterTime, err = meter.Float64Histogram("crawler_peerstore_iteration_time_seconds"); err != nil {
+		return nil, err
+	}
+	if t.ConnectedPeers, err = meter.Int64UpDownCounter("crawler_connected_peers"); err != nil {
+		return nil, err
+	}
+	if t.MeshSize, err = meter.Int64UpDownCounter("gossipsub_mesh_size"); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func newMeterProvider(cfg Config) (metric.MeterProvider, *http.Server, error) {
+	registry := prometheus.NewRegistry()
+	promExporter, err := otelprometheus.New(otelprometheus.WithRegisterer(registry))
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := []sdkmetric.Option{sdkmetric.WithReader(promExporter)}
+
+	if cfg.OTLPEndpoint != "" {
+		otlpExporter, err := newOTLPMetricExporter(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		opts = append(opts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(otlpExporter)))
+	}
+
+	provider := sdkmetric.NewMeterProvider(opts...)
+
+	var server *http.Server
+	if cfg.PrometheusAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+		server = &http.Server{Addr: cfg.PrometheusAddr, Handler: mux}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				Log.Errorf("prometheus endpoint stopped: %s", err.Error())
+			}
+		}()
+	}
+
+	return provider, server, nil
+}
+
+func newOTLPMetricExporter(cfg Config) (sdkmetric.Exporter, error) {
+	if cfg.OTLPProtocol == "http" {
+		return otlpmetrichttp.New(context.Background(), otlpmetrichttp.WithEndpoint(cfg.OTLPEndpoint))
+	}
+	return otlpmetricgrpc.New(context.Background(), otlpmetricgrpc.WithEndpoint(cfg.OTLPEndpoint))
+}
+
+func newTracerProvider(ctx context.Context, cfg Config) (*sdktrace.TracerProvider, error) {
+	if cfg.OTLPEndpoint == "" {
+		return sdktrace.NewTracerProvider(), nil
+	}
+	exporter, err := newOTLPSpanExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter)), nil
+}
+
+// StartSpan starts a span under the given name, used to wrap
+// JoinAndSubscribe, PeerStore.ConnectionAttemptEvent and
+// PeerStore.MessageEvent. MessageReadingLoop iterations get no span of
+// their own.
+func (t *Telemetry) StartSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, name)
+}
+
+// RecordConnectionAttempt records the latency of a single connection
+// attempt, successful or not.
+func (t *Telemetry) RecordConnectionAttempt(ctx context.Context, d time.Duration, succeed bool) {
+	t.ConnectionAttemptLatency.Record(ctx, d.Seconds(), metric.WithAttributes(
+		attrSucceed(succeed),
+	))
+}
+
+// RecordPeerstoreIteration records how long a full PeerStore iteration
+// took as a live histogram, alongside the single PeerstoreIterTime field
+// that PeerStore.NewPeerstoreIteration still sets.
+func (t *Telemetry) RecordPeerstoreIteration(ctx context.Context, d time.Duration) {
+	t.PeerstoreIterTime.Record(ctx, d.Seconds())
+}
+
+// RecordMessage increments the per-topic and per-peer message counters.
+func (t *Telemetry) RecordMessage(ctx context.Context, topic string, peerID string) {
+	t.MessagesPerTopic.Add(ctx, 1, metric.WithAttributes(attrTopic(topic)))
+	t.MessagesPerPeer.Add(ctx, 1, metric.WithAttributes(attrPeer(peerID)))
+}
+
+// SetMeshSize updates the gauge tracking how many peers are currently
+// meshed on a given topic.
+func (t *Telemetry) SetMeshSize(ctx context.Context, topic string, delta int64) {
+	t.MeshSize.Add(ctx, delta, metric.WithAttributes(attrTopic(topic)))
+}
+
+// SetConnectedPeers updates the gauge tracking how many peers are
+// currently connected, called on every ConnectionEvent/DisconnectionEvent.
+func (t *Telemetry) SetConnectedPeers(ctx context.Context, delta int64) {
+	t.ConnectedPeers.Add(ctx, delta)
+}
+
+// Close stops the Prometheus HTTP server, if one was started.
+func (t *Telemetry) Close(ctx context.Context) error {
+	if t.server == nil {
+		return nil
+	}
+	return t.server.Shutdown(ctx)
+}
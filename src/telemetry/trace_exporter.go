@@ -0,0 +1,18 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/trace"
+)
+
+// newOTLPSpanExporter builds the OTLP trace exporter matching cfg.OTLPProtocol.
+func newOTLPSpanExporter(ctx context.Context, cfg Config) (trace.SpanExporter, error) {
+	if cfg.OTLPProtocol == "http" {
+		return otlptrace.New(ctx, otlptracehttp.NewClient(otlptracehttp.WithEndpoint(cfg.OTLPEndpoint)))
+	}
+	return otlptrace.New(ctx, otlptracegrpc.NewClient(otlptracegrpc.WithEndpoint(cfg.OTLPEndpoint)))
+}